@@ -7,9 +7,30 @@ import (
 	"runtime"
 )
 
+// Configure applies the client config to the collectors of the current OS
+func Configure(config *models.Config) error {
+	return os.Configure(config)
+}
+
 func FetchData() (*models.System, error) {
 	if runtime.GOOS == "linux" {
 		return os.Linux()
 	}
 	return nil, fmt.Errorf("unsupported OS")
 }
+
+// Sensors lists the hardware sensors of the current OS along with their roles
+func Sensors() ([]models.Sensor, error) {
+	if runtime.GOOS == "linux" {
+		return os.LinuxSensors(), nil
+	}
+	return nil, fmt.Errorf("unsupported OS")
+}
+
+// Inventory describes the host the client runs on
+func Inventory() (*models.Inventory, error) {
+	if runtime.GOOS == "linux" {
+		return os.LinuxInventory()
+	}
+	return nil, fmt.Errorf("unsupported OS")
+}
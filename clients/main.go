@@ -1,23 +1,21 @@
 package main
 
 import (
+	"device-chronicle-client/fetch"
+	"device-chronicle-client/models"
+	"device-chronicle-client/push"
 	"device-chronicle-client/websocket"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"text/tabwriter"
 )
 
-type Config struct {
-	Server     string `json:"server"`
-	ClientName string `json:"client_name"`
-	Interval   int    `json:"interval"`
-	DummyData  bool   `json:"dummy_data"`
-}
-
 func main() {
 	// Define installation directories
 	homeDir, err := os.UserHomeDir()
@@ -29,6 +27,32 @@ func main() {
 	binDir := filepath.Join(homeDir, ".local", "bin")
 	binPath := filepath.Join(binDir, "chronicle-client")
 
+	// Load config if exists, command line args take precedence over it
+	config, err := loadConfig(configFile)
+	if err != nil {
+		log.Fatalf("Failed to parse config file: %v", err)
+	}
+
+	// Handle subcommands
+	if len(os.Args) > 1 && os.Args[1] == "push" {
+		message, err := push.ParseArgs(os.Args[2:])
+		if err != nil {
+			log.Fatalf("Usage: chronicle-client push key=value... [--tag key=value] [--event type [--message text]]: %v", err)
+		}
+		if err := push.Send(push.SocketPath(), message); err != nil {
+			log.Fatalf("Failed to push: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "sensors" {
+		if err := fetch.Configure(&config); err != nil {
+			log.Fatalf("Invalid config: %v", err)
+		}
+		printSensors()
+		os.Exit(0)
+	}
+
 	// Define flags
 	serverAddr := flag.String("server", "", "Server address, e.g. http://localhost:8000")
 	dummyData := flag.Bool("dummy", false, "Use dummy data instead of real data for testing")
@@ -43,13 +67,11 @@ func main() {
 			log.Fatalln("Server address and client name are required for installation")
 		}
 
-		// Create configuration
-		config := Config{
-			Server:     *serverAddr,
-			ClientName: *clientName,
-			Interval:   *interval,
-			DummyData:  *dummyData,
-		}
+		// Create configuration, keeping any other sections of an existing config
+		config.Server = *serverAddr
+		config.ClientName = *clientName
+		config.Interval = *interval
+		config.DummyData = *dummyData
 
 		// Create directories
 		os.MkdirAll(configDir, 0755)
@@ -113,27 +135,16 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Load config if exists and no command line args provided
-	if (*serverAddr == "" || *clientName == "") && fileExists(configFile) {
+	// Use config values if command line args aren't provided
+	if fileExists(configFile) {
 		fmt.Println("Loading configuration from file...")
-		configData, err := os.ReadFile(configFile)
-		if err != nil {
-			log.Fatalf("Failed to read config file: %v", err)
-		}
-
-		var config Config
-		if err := json.Unmarshal(configData, &config); err != nil {
-			log.Fatalf("Failed to parse config file: %v", err)
-		}
-
-		// Use config values if command line args aren't provided
 		if *serverAddr == "" {
 			*serverAddr = config.Server
 		}
 		if *clientName == "" {
 			*clientName = config.ClientName
 		}
-		if flag.Lookup("interval").DefValue == fmt.Sprint(*interval) {
+		if flag.Lookup("interval").DefValue == fmt.Sprint(*interval) && config.Interval > 0 {
 			*interval = config.Interval
 		}
 		if flag.Lookup("dummy").DefValue == fmt.Sprint(*dummyData) {
@@ -150,8 +161,62 @@ func main() {
 		log.Fatalln("Client name is required. Usage: ./chronicle-client --client desktop")
 	}
 
+	if err := fetch.Configure(&config); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	// Accept metrics and events from local scripts, the client still works without it
+	pushServer, err := push.Listen(push.SocketPath())
+	if err != nil {
+		log.Println("Local push API disabled:", err)
+	} else {
+		defer pushServer.Close()
+	}
+
 	// Run the client
-	websocket.Websocket(serverAddr, dummyData, interval, clientName)
+	websocket.Websocket(serverAddr, dummyData, interval, clientName, pushServer)
+}
+
+// loadConfig reads the config file, returning an empty config if it doesn't exist
+func loadConfig(path string) (models.Config, error) {
+	var config models.Config
+	configData, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(configData, &config)
+	return config, err
+}
+
+// printSensors lists every detected sensor with its current reading and role,
+// to help write the "sensors" section of the config
+func printSensors() {
+	sensors, err := fetch.Sensors()
+	if err != nil {
+		log.Fatalf("Failed to list sensors: %v", err)
+	}
+	if len(sensors) == 0 {
+		fmt.Println("No sensors found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCHIP\tLABEL\tVALUE\tROLE\tSOURCE\tNAME")
+	for _, sensor := range sensors {
+		role, source := sensor.Role, "guess"
+		if sensor.Configured {
+			source = "config"
+		}
+		if role == "" {
+			role = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f %s\t%s\t%s\t%s\n",
+			sensor.ID, sensor.Chip, sensor.Label, sensor.Value, sensor.Unit, role, source, sensor.Name)
+	}
+	w.Flush()
 }
 
 func fileExists(path string) bool {
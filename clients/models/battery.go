@@ -0,0 +1,12 @@
+package models
+
+// Battery is the state of a system battery (not of peripherals such as wireless mice)
+type Battery struct {
+	Name        string  `json:"name"`                    // e.g. BAT0
+	Status      string  `json:"status"`                  // Charging, Discharging, Full or Not charging
+	Capacity    float64 `json:"capacity"`                // Percent
+	RateWatts   float64 `json:"rate_watts"`              // Positive while charging, negative while discharging
+	TimeToEmpty int64   `json:"time_to_empty,omitempty"` // Seconds, only while discharging
+	CycleCount  int     `json:"cycle_count,omitempty"`
+	Health      float64 `json:"health,omitempty"` // Full capacity relative to design capacity, percent
+}
@@ -0,0 +1,97 @@
+package models
+
+// Config is the client configuration, stored in ~/.config/chronicle-client/config.json
+type Config struct {
+	Server     string `json:"server"`
+	ClientName string `json:"client_name"`
+	Interval   int    `json:"interval"`
+	DummyData  bool   `json:"dummy_data"`
+
+	// Sensors maps a sensor ID ("k10temp/temp1"), key ("k10temp_tctl") or hwmon label ("Tctl") to a role
+	Sensors map[string]SensorConfig `json:"sensors,omitempty"`
+
+	// Probes are reachability checks run from this client, e.g. whether the NAS answers
+	Probes []ProbeConfig `json:"probes,omitempty"`
+
+	// Textfile reads metrics that scripts and cron jobs write to a directory
+	Textfile *TextfileConfig `json:"textfile,omitempty"`
+
+	// Plugins are commands that print metrics, each run on its own schedule
+	Plugins []PluginConfig `json:"plugins,omitempty"`
+
+	// MangoHud reads the frame logs MangoHud writes while a game runs
+	MangoHud *MangoHudConfig `json:"mangohud,omitempty"`
+
+	// GameProcesses are the process names that start a gaming session, replacing the default launchers
+	GameProcesses []string `json:"game_processes,omitempty"`
+
+	// Updates enables checking for pending OS updates, by default with the detected package manager
+	Updates *UpdatesConfig `json:"updates,omitempty"`
+
+	// Auth enables counting failed SSH and sudo logins
+	Auth *AuthConfig `json:"auth,omitempty"`
+
+	// SMART enables reading drive health with smartctl
+	SMART *SMARTConfig `json:"smart,omitempty"`
+}
+
+// SensorConfig assigns a role and an optional display name to a sensor
+type SensorConfig struct {
+	Role string `json:"role"` // cpu, chipset, nvme, gpu-ambient or ignore
+	Name string `json:"name,omitempty"`
+}
+
+// ProbeConfig is a TCP connect or HTTP GET check run every interval
+type ProbeConfig struct {
+	Name         string `json:"name"`                    // Used in metric keys, e.g. "nas" reports probe_nas_up
+	Type         string `json:"type"`                    // tcp or http
+	Target       string `json:"target"`                  // host:port for tcp, URL for http
+	Timeout      int    `json:"timeout,omitempty"`       // Seconds, defaults to 5
+	ExpectStatus int    `json:"expect_status,omitempty"` // http only, defaults to any 2xx or 3xx
+	ExpectBody   string `json:"expect_body,omitempty"`   // http only, substring the body must contain
+}
+
+// TextfileConfig is a directory of *.prom (Prometheus text format) and *.json metric files
+type TextfileConfig struct {
+	Directory string `json:"directory"`
+	Namespace string `json:"namespace,omitempty"` // Prefix of the metric keys, defaults to "textfile"
+}
+
+// PluginConfig is a command that prints metrics as JSON or Prometheus text on stdout
+type PluginConfig struct {
+	Name     string   `json:"name"`               // Metric keys are prefixed with plugin_<name>_
+	Command  []string `json:"command"`            // Program and arguments, not run through a shell
+	Interval int      `json:"interval,omitempty"` // Seconds between runs, defaults to 60
+	Timeout  int      `json:"timeout,omitempty"`  // Seconds, defaults to 10
+	Env      []string `json:"env,omitempty"`      // KEY=value pairs, the command gets no other environment besides PATH, HOME and LANG
+}
+
+// MangoHudConfig is the directory MangoHud writes its CSV logs to (its output_folder option)
+type MangoHudConfig struct {
+	Directory string `json:"directory"`
+}
+
+// UpdatesConfig controls the pending OS updates check. Commands left out use the
+// defaults of the detected package manager (apt, dnf or pacman).
+type UpdatesConfig struct {
+	Interval        int      `json:"interval,omitempty"`         // Seconds between checks, defaults to 3600
+	Timeout         int      `json:"timeout,omitempty"`          // Seconds per command, defaults to 300
+	UpdatesCommand  []string `json:"updates_command,omitempty"`  // Prints the number of pending updates
+	SecurityCommand []string `json:"security_command,omitempty"` // Prints the number of pending security updates
+	RebootCommand   []string `json:"reboot_command,omitempty"`   // Exits with status 1 when a reboot is required
+}
+
+// AuthConfig is where failed logins are read from
+type AuthConfig struct {
+	Source string `json:"source"`         // journald or file
+	File   string `json:"file,omitempty"` // file only, defaults to /var/log/auth.log
+}
+
+// SMARTConfig controls the drive health check with smartctl, which needs root to read
+// most drives, e.g. through sudo with a NOPASSWD rule for smartctl
+type SMARTConfig struct {
+	Command  []string `json:"command,omitempty"`  // Defaults to smartctl, e.g. ["sudo", "-n", "smartctl"]
+	Devices  []string `json:"devices,omitempty"`  // Defaults to every disk in /sys/block
+	Interval int      `json:"interval,omitempty"` // Seconds between checks, defaults to 3600
+	Timeout  int      `json:"timeout,omitempty"`  // Seconds per drive, defaults to 30
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Event is a discrete occurrence reported alongside a sample, e.g. a process killed by the OOM killer
+type Event struct {
+	Type      string            `json:"type"`
+	Timestamp int64             `json:"timestamp"` // Unix seconds
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// NewEvent creates an Event stamped with the current time
+func NewEvent(eventType, message string, details map[string]string) Event {
+	return Event{
+		Type:      eventType,
+		Timestamp: time.Now().Unix(),
+		Message:   message,
+		Details:   details,
+	}
+}
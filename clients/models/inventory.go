@@ -0,0 +1,34 @@
+package models
+
+// Inventory is the static description of a host, sent when the client connects and whenever it changes
+type Inventory struct {
+	Hostname     string          `json:"hostname"`
+	OS           string          `json:"os"`     // e.g. linux
+	Distro       string          `json:"distro"` // e.g. ubuntu 24.04
+	Kernel       string          `json:"kernel"`
+	Architecture string          `json:"architecture"`
+	CPUModel     string          `json:"cpu_model"`
+	Microcode    string          `json:"microcode,omitempty"`
+	CPUCores     int             `json:"cpu_cores"`   // Physical cores
+	CPUThreads   int             `json:"cpu_threads"` // Logical CPUs
+	TotalRAM     uint64          `json:"total_ram"`   // Bytes
+	Disks        []InventoryDisk `json:"disks"`
+	NICs         []InventoryNIC  `json:"nics"`
+	AgentVersion string          `json:"agent_version"`
+}
+
+// InventoryDisk is a physical block device
+type InventoryDisk struct {
+	Name       string `json:"name"`
+	Model      string `json:"model,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	Size       uint64 `json:"size"` // Bytes
+	Rotational bool   `json:"rotational"`
+}
+
+// InventoryNIC is a physical network interface
+type InventoryNIC struct {
+	Name   string `json:"name"`
+	MAC    string `json:"mac"`
+	Driver string `json:"driver,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// Sensor is a single hwmon reading such as a temperature, fan speed, voltage or power draw
+type Sensor struct {
+	ID    string  `json:"id"` // Stable across reboots, e.g. "k10temp/temp1"
+	Chip  string  `json:"chip"`
+	Label string  `json:"label"`
+	Kind  string  `json:"kind"` // temp, fan, in or power
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+	Max   float64 `json:"max,omitempty"`
+	Crit  float64 `json:"crit,omitempty"`
+
+	// Role is what the sensor measures (cpu, chipset, nvme, gpu-ambient), either
+	// configured by the user or guessed from its chip and label
+	Role       string `json:"role,omitempty"`
+	Name       string `json:"name,omitempty"` // Display name from the user's config
+	Configured bool   `json:"-"`
+}
@@ -12,6 +12,8 @@ type System struct {
 	UsedRAMPercentage  string `json:"used_ram_percentage"`
 	Hostname           string `json:"hostname"`
 	Uptime             string `json:"uptime"`
+	BootTime           int64  `json:"boot_time"` // Unix seconds, lets the server detect reboots
+	UptimeSeconds      uint64 `json:"uptime_seconds"`
 	LoadAvg1           string `json:"load_1"`
 	LoadAvg5           string `json:"load_5"`
 	LoadAvg15          string `json:"load_15"`
@@ -29,6 +31,19 @@ type System struct {
 	// Dynamic fields
 	CPUCores map[string]string      `json:"cpu_cores"`
 	Custom   map[string]interface{} `json:"custom,omitempty"`
+
+	// Every hwmon sensor found on the system
+	Sensors []Sensor `json:"sensors,omitempty"`
+
+	// Power supplies, only reported on devices that have a battery
+	ACOnline  *bool     `json:"ac_online,omitempty"`
+	Batteries []Battery `json:"batteries,omitempty"`
+
+	// Discrete events detected since the previous sample
+	Events []Event `json:"events,omitempty"`
+
+	// Labels attached to this sample, e.g. game=cs2 pushed by a local script
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // NewSystem creates a new System with initialized maps
@@ -53,6 +68,8 @@ func (s *System) ToMap() map[string]interface{} {
 	result["used_ram_percentage"] = s.UsedRAMPercentage
 	result["hostname"] = s.Hostname
 	result["uptime"] = s.Uptime
+	result["boot_time"] = s.BootTime
+	result["uptime_seconds"] = s.UptimeSeconds
 	result["load_1"] = s.LoadAvg1
 	result["load_5"] = s.LoadAvg5
 	result["load_15"] = s.LoadAvg15
@@ -76,5 +93,25 @@ func (s *System) ToMap() map[string]interface{} {
 		result[k] = v
 	}
 
+	if len(s.Sensors) > 0 {
+		result["sensors"] = s.Sensors
+	}
+
+	if s.ACOnline != nil {
+		result["ac_online"] = *s.ACOnline
+	}
+
+	if len(s.Batteries) > 0 {
+		result["batteries"] = s.Batteries
+	}
+
+	if len(s.Events) > 0 {
+		result["events"] = s.Events
+	}
+
+	if len(s.Tags) > 0 {
+		result["tags"] = s.Tags
+	}
+
 	return result
 }
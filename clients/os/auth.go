@@ -0,0 +1,316 @@
+package os
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"device-chronicle-client/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Sources of failed logins
+const (
+	AuthSourceJournald = "journald"
+	AuthSourceFile     = "file"
+)
+
+const (
+	defaultAuthFile  = "/var/log/auth.log"
+	authRateWindow   = 5 * time.Minute // Failures per minute are averaged over this window
+	authSourceWindow = time.Hour       // Failures per source IP are counted over this window
+	maxAuthSources   = 20              // Source IPs reported, the ones with the most failures
+	maxAuthFailures  = 10000           // Failures remembered, the oldest are forgotten first
+	journalTimeout   = 5 * time.Second
+)
+
+var (
+	// sshd logs every failed attempt, for existing and unknown users alike.
+	// OpenSSH 9.8 moved authentication to sshd-session.
+	sshFailedPattern      = regexp.MustCompile(`^Failed \S+ for (?:invalid user )?(\S*) from (\S+) port \d+`)
+	sshInvalidUserPattern = regexp.MustCompile(`^Invalid user (\S*) from (\S+)`)
+	sudoFailedPattern     = regexp.MustCompile(`^\s*(\S+) : (?:(\d+) incorrect password attempts?|user NOT in sudoers)`)
+
+	// A syslog line in auth.log, with either a traditional or an RFC 3339 timestamp
+	authLogPattern = regexp.MustCompile(`^(?:\w{3}\s+\d+\s+[\d:]+|\d{4}-\d{2}-\d{2}T\S+)\s+\S+\s+([\w.-]+)(?:\[\d+\])?:\s+(.*)$`)
+)
+
+// authFailure is a failed login, or for sudo the wrong passwords of one run
+type authFailure struct {
+	Time     time.Time
+	Kind     string // ssh, invalid_user or sudo
+	Source   string // Remote IP, empty for sudo
+	Attempts int    // Failed attempts the entry records
+}
+
+// authEntry is a log message that may record a failed login
+type authEntry struct {
+	Identifier string // Program that logged it, e.g. sshd
+	Message    string
+}
+
+var (
+	authConfig *models.AuthConfig
+
+	// Where reading continues, the end of the log when collection started
+	authFileOffset   int64
+	authFileInfo     os.FileInfo
+	authJournalSince time.Time
+	authCursor       string
+
+	authFailures []authFailure
+)
+
+// configureAuth validates and applies the failed login collection from the config
+func configureAuth(config *models.AuthConfig) error {
+	if config != nil {
+		switch config.Source {
+		case AuthSourceJournald:
+		case AuthSourceFile:
+			if config.File == "" {
+				config.File = defaultAuthFile
+			}
+		default:
+			return fmt.Errorf("auth source must be %s or %s", AuthSourceJournald, AuthSourceFile)
+		}
+	}
+
+	authConfig = config
+	authFileOffset, authFileInfo = -1, nil
+	authJournalSince, authCursor = time.Time{}, ""
+	authFailures = nil
+	return nil
+}
+
+// parseAuthEntry returns the failed login a log message records, if any. Unknown user
+// names tried over SSH are reported as a failure with the kind "invalid_user". sudo logs
+// the number of wrong passwords once the user gives up or runs out of tries.
+func parseAuthEntry(entry authEntry) (authFailure, bool) {
+	switch entry.Identifier {
+	case "sshd", "sshd-session":
+		if match := sshFailedPattern.FindStringSubmatch(entry.Message); match != nil {
+			return authFailure{Kind: "ssh", Source: match[2], Attempts: 1}, true
+		}
+		if match := sshInvalidUserPattern.FindStringSubmatch(entry.Message); match != nil {
+			return authFailure{Kind: "invalid_user", Source: match[2], Attempts: 1}, true
+		}
+	case "sudo":
+		if match := sudoFailedPattern.FindStringSubmatch(entry.Message); match != nil {
+			attempts := 1
+			if match[2] != "" {
+				attempts, _ = strconv.Atoi(match[2])
+			}
+			return authFailure{Kind: "sudo", Attempts: max(attempts, 1)}, true
+		}
+	}
+	return authFailure{}, false
+}
+
+// parseAuthLog splits auth.log lines into entries, skipping lines that aren't syslog messages
+func parseAuthLog(data []byte) []authEntry {
+	var entries []authEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if match := authLogPattern.FindStringSubmatch(scanner.Text()); match != nil {
+			entries = append(entries, authEntry{Identifier: match[1], Message: match[2]})
+		}
+	}
+	return entries
+}
+
+// parseJournal reads journalctl's JSON output, one object per line, returning the
+// entries and the cursor of the last one. Binary messages are skipped.
+func parseJournal(data []byte) ([]authEntry, string) {
+	var entries []authEntry
+	var cursor string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMetricsSize)
+	for scanner.Scan() {
+		var record struct {
+			Cursor     string          `json:"__CURSOR"`
+			Identifier string          `json:"SYSLOG_IDENTIFIER"`
+			Message    json.RawMessage `json:"MESSAGE"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		cursor = record.Cursor
+
+		var message string
+		if err := json.Unmarshal(record.Message, &message); err != nil {
+			continue
+		}
+		entries = append(entries, authEntry{Identifier: record.Identifier, Message: message})
+	}
+	return entries, cursor
+}
+
+// readAuthFile reads the entries appended to the log file since the previous call. The first
+// call only finds the end of the file, a rotated or truncated file is read from the start.
+func readAuthFile(path string) ([]authEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if authFileOffset < 0 {
+		authFileOffset, authFileInfo = info.Size(), info
+		return nil, nil
+	}
+	if !os.SameFile(info, authFileInfo) || info.Size() < authFileOffset {
+		authFileOffset = 0
+	}
+	authFileInfo = info
+
+	if _, err := file.Seek(authFileOffset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxMetricsSize))
+	if err != nil {
+		return nil, err
+	}
+	// Only complete lines, the rest is read once it was written
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, nil
+	}
+	authFileOffset += int64(end + 1)
+	return parseAuthLog(data[:end+1]), nil
+}
+
+// readJournal reads the sshd and sudo entries logged since the previous call. Reading
+// the journal requires membership of the systemd-journal or adm group.
+func readJournal(now time.Time) ([]authEntry, error) {
+	args := []string{"--no-pager", "--output=json", "-t", "sshd", "-t", "sshd-session", "-t", "sudo"}
+	if authCursor != "" {
+		args = append(args, "--after-cursor="+authCursor)
+	} else {
+		if authJournalSince.IsZero() {
+			authJournalSince = now
+		}
+		args = append(args, fmt.Sprintf("--since=@%d", authJournalSince.Unix()))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), journalTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "journalctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl: %w", err)
+	}
+
+	entries, cursor := parseJournal(output)
+	if cursor != "" {
+		authCursor = cursor
+	}
+	return entries, nil
+}
+
+// recordAuthEntries adds the failed logins among entries to the history, one per attempt,
+// and returns how many of every kind there were
+func recordAuthEntries(entries []authEntry, now time.Time) map[string]int {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		failure, ok := parseAuthEntry(entry)
+		if !ok {
+			continue
+		}
+		counts[failure.Kind] += failure.Attempts
+		// The failed attempt that follows is what gets counted against the source
+		if failure.Kind == "invalid_user" {
+			continue
+		}
+		failure.Time = now
+		for i := 0; i < failure.Attempts; i++ {
+			authFailures = append(authFailures, failure)
+		}
+	}
+
+	// Forget what fell out of the source window
+	start := 0
+	for start < len(authFailures) && now.Sub(authFailures[start].Time) > authSourceWindow {
+		start++
+	}
+	if len(authFailures)-start > maxAuthFailures {
+		start = len(authFailures) - maxAuthFailures
+	}
+	authFailures = authFailures[start:]
+	return counts
+}
+
+// authRate returns the failures of a kind per minute over authRateWindow
+func authRate(kind string, now time.Time) float64 {
+	count := 0
+	for _, failure := range authFailures {
+		if failure.Kind == kind && now.Sub(failure.Time) < authRateWindow {
+			count++
+		}
+	}
+	return float64(count) / authRateWindow.Minutes()
+}
+
+// authSources returns the failures per source IP over authSourceWindow, limited to
+// the maxAuthSources sources with the most failures
+func authSources() map[string]int {
+	counts := map[string]int{}
+	for _, failure := range authFailures {
+		if failure.Source != "" {
+			counts[failure.Source]++
+		}
+	}
+
+	sources := make([]string, 0, len(counts))
+	for source := range counts {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		if counts[sources[i]] != counts[sources[j]] {
+			return counts[sources[i]] > counts[sources[j]]
+		}
+		return sources[i] < sources[j]
+	})
+	for _, source := range sources[min(len(sources), maxAuthSources):] {
+		delete(counts, source)
+	}
+	return counts
+}
+
+// collectAuthData reports the failed SSH and sudo logins since the previous sample, their
+// rate, and the source IPs failing the most. Nothing is collected unless configured.
+func collectAuthData(s *models.System) {
+	if authConfig == nil {
+		return
+	}
+
+	now := time.Now()
+	var entries []authEntry
+	var err error
+	if authConfig.Source == AuthSourceJournald {
+		entries, err = readJournal(now)
+	} else {
+		entries, err = readAuthFile(authConfig.File)
+	}
+	if err != nil {
+		s.Custom["auth_error"] = err.Error()
+	}
+
+	counts := recordAuthEntries(entries, now)
+	s.Custom["auth_ssh_failed"] = counts["ssh"]
+	s.Custom["auth_ssh_invalid_users"] = counts["invalid_user"]
+	s.Custom["auth_sudo_failed"] = counts["sudo"]
+	s.Custom["auth_ssh_failed_rate"] = math.Round(authRate("ssh", now)*10) / 10
+	s.Custom["auth_sudo_failed_rate"] = math.Round(authRate("sudo", now)*10) / 10
+	s.Custom["auth_failed_sources"] = authSources()
+}
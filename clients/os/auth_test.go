@@ -0,0 +1,116 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigureAuth(t *testing.T) {
+	defer configureAuth(nil)
+
+	config := &models.AuthConfig{Source: AuthSourceFile}
+	require.NoError(t, configureAuth(config))
+	assert.Equal(t, defaultAuthFile, config.File)
+	require.NoError(t, configureAuth(&models.AuthConfig{Source: AuthSourceJournald}))
+	assert.Error(t, configureAuth(&models.AuthConfig{Source: "syslog"}))
+}
+
+func TestParseAuthLog(t *testing.T) {
+	data, err := os.ReadFile("testdata/auth.log")
+	require.NoError(t, err)
+
+	var failures []authFailure
+	for _, entry := range parseAuthLog(data) {
+		if failure, ok := parseAuthEntry(entry); ok {
+			failures = append(failures, failure)
+		}
+	}
+	assert.Equal(t, []authFailure{
+		{Kind: "invalid_user", Source: "203.0.113.7", Attempts: 1},
+		{Kind: "ssh", Source: "203.0.113.7", Attempts: 1},
+		{Kind: "ssh", Source: "203.0.113.7", Attempts: 1},
+		{Kind: "ssh", Source: "198.51.100.23", Attempts: 1},
+		// 3 incorrect password attempts in one sudo run
+		{Kind: "sudo", Attempts: 3},
+		{Kind: "sudo", Attempts: 1},
+		{Kind: "ssh", Source: "2001:db8::5", Attempts: 1},
+	}, failures)
+}
+
+func TestParseJournal(t *testing.T) {
+	data, err := os.ReadFile("testdata/journal.json")
+	require.NoError(t, err)
+
+	entries, cursor := parseJournal(data)
+	assert.Equal(t, "s=1;i=14", cursor)
+	// The binary message is skipped
+	require.Len(t, entries, 4)
+	assert.Equal(t, authEntry{Identifier: "sshd-session", Message: "Failed password for root from 192.0.2.10 port 4030 ssh2"}, entries[2])
+
+	counts := recordAuthEntries(entries, time.Now())
+	defer configureAuth(nil)
+	assert.Equal(t, map[string]int{"invalid_user": 1, "ssh": 2, "sudo": 1}, counts)
+}
+
+func TestCollectAuthDataFile(t *testing.T) {
+	defer configureAuth(nil)
+
+	path := filepath.Join(t.TempDir(), "auth.log")
+	require.NoError(t, os.WriteFile(path, []byte("May  1 20:00:00 pi sshd[1]: Failed password for root from 192.0.2.1 port 1 ssh2\n"), 0o644))
+	require.NoError(t, configureAuth(&models.AuthConfig{Source: AuthSourceFile, File: path}))
+
+	// The first sample starts at the end of the log
+	s := &models.System{Custom: map[string]interface{}{}}
+	collectAuthData(s)
+	assert.Equal(t, 0, s.Custom["auth_ssh_failed"])
+	assert.Empty(t, s.Custom["auth_failed_sources"])
+
+	data, err := os.ReadFile("testdata/auth.log")
+	require.NoError(t, err)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	// The incomplete last line waits for the next sample
+	_, err = file.Write(append(data, "May  1 20:20:00 pi sshd[1500]: Failed password for root from 203.0.113.7"...))
+	require.NoError(t, err)
+
+	s = &models.System{Custom: map[string]interface{}{}}
+	collectAuthData(s)
+	assert.Equal(t, 4, s.Custom["auth_ssh_failed"])
+	assert.Equal(t, 1, s.Custom["auth_ssh_invalid_users"])
+	assert.Equal(t, 4, s.Custom["auth_sudo_failed"])
+	assert.Equal(t, 0.8, s.Custom["auth_ssh_failed_rate"])
+	assert.Equal(t, 0.8, s.Custom["auth_sudo_failed_rate"])
+	assert.Equal(t, map[string]int{"203.0.113.7": 2, "198.51.100.23": 1, "2001:db8::5": 1}, s.Custom["auth_failed_sources"])
+	assert.NotContains(t, s.Custom, "auth_error")
+
+	_, err = file.Write([]byte(" port 1 ssh2\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	s = &models.System{Custom: map[string]interface{}{}}
+	collectAuthData(s)
+	assert.Equal(t, 1, s.Custom["auth_ssh_failed"])
+	assert.Equal(t, map[string]int{"203.0.113.7": 3, "198.51.100.23": 1, "2001:db8::5": 1}, s.Custom["auth_failed_sources"])
+
+	// A rotated log is read from its start
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.WriteFile(path, []byte("May  2 00:00:01 pi sudo:   pi : user NOT in sudoers ; USER=root ; COMMAND=/bin/sh\n"), 0o644))
+	s = &models.System{Custom: map[string]interface{}{}}
+	collectAuthData(s)
+	assert.Equal(t, 0, s.Custom["auth_ssh_failed"])
+	assert.Equal(t, 1, s.Custom["auth_sudo_failed"])
+}
+
+func TestCollectAuthDataMissingFile(t *testing.T) {
+	defer configureAuth(nil)
+	require.NoError(t, configureAuth(&models.AuthConfig{Source: AuthSourceFile, File: "testdata/missing.log"}))
+
+	s := &models.System{Custom: map[string]interface{}{}}
+	collectAuthData(s)
+	assert.Contains(t, s.Custom["auth_error"], "missing.log")
+}
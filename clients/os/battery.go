@@ -0,0 +1,102 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"math"
+	"path/filepath"
+	"strconv"
+)
+
+// readPowerSupplyFloat reads a numeric power_supply attribute, reporting whether it exists
+func readPowerSupplyFloat(supply, attribute string) (float64, bool) {
+	value, err := readSysfsString(filepath.Join(supply, attribute))
+	if err != nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
+
+// readBattery reads a battery from its /sys/class/power_supply directory. Batteries report
+// either energy_* (µWh) and power_now (µW), or charge_* (µAh) and current_now (µA),
+// which are converted to energy using the voltage.
+func readBattery(supply string) models.Battery {
+	battery := models.Battery{Name: filepath.Base(supply)}
+	battery.Status, _ = readSysfsString(filepath.Join(supply, "status"))
+	battery.Capacity, _ = readPowerSupplyFloat(supply, "capacity")
+	if cycles, ok := readPowerSupplyFloat(supply, "cycle_count"); ok {
+		battery.CycleCount = int(cycles)
+	}
+
+	voltage, ok := readPowerSupplyFloat(supply, "voltage_now")
+	if !ok {
+		voltage, _ = readPowerSupplyFloat(supply, "voltage_min_design")
+	}
+	voltage /= 1e6 // µV to V
+
+	// Energy in Wh and power in W
+	energyNow, hasEnergy := readPowerSupplyFloat(supply, "energy_now")
+	energyFull, _ := readPowerSupplyFloat(supply, "energy_full")
+	energyDesign, _ := readPowerSupplyFloat(supply, "energy_full_design")
+	power, hasPower := readPowerSupplyFloat(supply, "power_now")
+	if !hasEnergy {
+		chargeNow, _ := readPowerSupplyFloat(supply, "charge_now")
+		chargeFull, _ := readPowerSupplyFloat(supply, "charge_full")
+		chargeDesign, _ := readPowerSupplyFloat(supply, "charge_full_design")
+		energyNow, energyFull, energyDesign = chargeNow*voltage, chargeFull*voltage, chargeDesign*voltage
+	}
+	if !hasPower {
+		current, _ := readPowerSupplyFloat(supply, "current_now")
+		power = current * voltage
+	}
+	energyNow, energyFull, energyDesign, power = energyNow/1e6, energyFull/1e6, energyDesign/1e6, math.Abs(power)/1e6
+
+	if energyDesign > 0 {
+		battery.Health = math.Round(energyFull/energyDesign*1000) / 10
+	}
+
+	battery.RateWatts = math.Round(power*100) / 100
+	if battery.Status == "Discharging" {
+		battery.RateWatts = -battery.RateWatts
+
+		if seconds, ok := readPowerSupplyFloat(supply, "time_to_empty_now"); ok {
+			battery.TimeToEmpty = int64(seconds)
+		} else if power > 0 {
+			battery.TimeToEmpty = int64(energyNow / power * 3600)
+		}
+	}
+	return battery
+}
+
+// collectBatteryData reports AC adapter state and every system battery. Desktops
+// without a battery report nothing.
+func collectBatteryData(s *models.System) {
+	supplies, _ := filepath.Glob(filepath.Join(sysRoot, "class/power_supply/*"))
+
+	acFound, acOnline := false, false
+	for _, supply := range supplies {
+		supplyType, err := readSysfsString(filepath.Join(supply, "type"))
+		if err != nil {
+			continue
+		}
+
+		// Peripherals (mice, headsets, controllers) report scope "Device"
+		if scope, err := readSysfsString(filepath.Join(supply, "scope")); err == nil && scope == "Device" {
+			continue
+		}
+
+		switch supplyType {
+		case "Mains", "USB":
+			if online, ok := readPowerSupplyFloat(supply, "online"); ok {
+				acFound = true
+				acOnline = acOnline || online == 1
+			}
+		case "Battery":
+			s.Batteries = append(s.Batteries, readBattery(supply))
+		}
+	}
+
+	if acFound && len(s.Batteries) > 0 {
+		s.ACOnline = &acOnline
+	}
+}
@@ -0,0 +1,58 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCollectBatteryData(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectBatteryData(s)
+
+	require.NotNil(t, s.ACOnline)
+	assert.False(t, *s.ACOnline)
+
+	// The wireless mouse battery is skipped
+	require.Len(t, s.Batteries, 2)
+
+	// Energy based battery, 21 Wh left at 10.5 W
+	assert.Equal(t, models.Battery{
+		Name:        "BAT0",
+		Status:      "Discharging",
+		Capacity:    42,
+		RateWatts:   -10.5,
+		TimeToEmpty: 7200,
+		CycleCount:  123,
+		Health:      90,
+	}, s.Batteries[0])
+
+	// Charge based battery, 1 A at 12 V
+	assert.Equal(t, models.Battery{
+		Name:      "BAT1",
+		Status:    "Charging",
+		Capacity:  80,
+		RateWatts: 12,
+		Health:    100,
+	}, s.Batteries[1])
+
+	result := s.ToMap()
+	assert.Equal(t, false, result["ac_online"])
+	assert.Len(t, result["batteries"], 2)
+}
+
+func TestCollectBatteryDataDesktop(t *testing.T) {
+	sysRoot = "testdata/empty"
+	defer func() { sysRoot = "/sys" }()
+
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectBatteryData(s)
+
+	assert.Nil(t, s.ACOnline)
+	assert.Empty(t, s.Batteries)
+	assert.NotContains(t, s.ToMap(), "ac_online")
+}
@@ -1,8 +1,38 @@
 package os
 
-import "runtime"
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// procRoot and sysRoot are the mount points of procfs and sysfs.
+// Tests point them at fixture trees under testdata.
+var (
+	procRoot = "/proc"
+	sysRoot  = "/sys"
+)
 
 // isARM returns true if running on ARM architecture
 func isARM() bool {
 	return runtime.GOARCH == "arm" || runtime.GOARCH == "arm64"
 }
+
+// readSysfsString reads a single-value sysfs file without the trailing newline
+func readSysfsString(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readSysfsUint reads a single unsigned integer from a sysfs file
+func readSysfsUint(path string) (uint64, error) {
+	value, err := readSysfsString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
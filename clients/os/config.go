@@ -0,0 +1,32 @@
+package os
+
+import "device-chronicle-client/models"
+
+// Configure applies the parts of the client config that affect data collection
+func Configure(config *models.Config) error {
+	if err := configureSensors(config.Sensors); err != nil {
+		return err
+	}
+	if err := configureProbes(config.Probes); err != nil {
+		return err
+	}
+	if err := configureTextfile(config.Textfile); err != nil {
+		return err
+	}
+	if err := configurePlugins(config.Plugins); err != nil {
+		return err
+	}
+	if err := configureMangoHud(config.MangoHud); err != nil {
+		return err
+	}
+	if err := configureGames(config.GameProcesses); err != nil {
+		return err
+	}
+	if err := configureUpdates(config.Updates); err != nil {
+		return err
+	}
+	if err := configureAuth(config.Auth); err != nil {
+		return err
+	}
+	return configureSMART(config.SMART)
+}
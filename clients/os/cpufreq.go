@@ -0,0 +1,96 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cpuFreq is the cpufreq and thermal throttle state of a single logical CPU
+type cpuFreq struct {
+	CPU                  int
+	CurMHz               float64
+	MinMHz               float64
+	MaxMHz               float64
+	Governor             string
+	EnergyPerfPreference string
+	CoreThrottleCount    *uint64 // nil if the platform doesn't expose thermal_throttle
+	PackageThrottleCount *uint64
+}
+
+// readCPUFreqs reads per-CPU frequencies from /sys/devices/system/cpu/cpuN/cpufreq.
+// CPUs without a cpufreq directory (offline, or no cpufreq driver) are skipped.
+func readCPUFreqs() []cpuFreq {
+	paths, _ := filepath.Glob(filepath.Join(sysRoot, "devices/system/cpu/cpu[0-9]*"))
+
+	freqs := []cpuFreq{}
+	for _, path := range paths {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(path), "cpu"))
+		if err != nil {
+			continue
+		}
+
+		// Frequencies are reported in kHz
+		cur, err := readSysfsUint(filepath.Join(path, "cpufreq/scaling_cur_freq"))
+		if err != nil {
+			continue
+		}
+		freq := cpuFreq{CPU: cpu, CurMHz: float64(cur) / 1000}
+		if min, err := readSysfsUint(filepath.Join(path, "cpufreq/scaling_min_freq")); err == nil {
+			freq.MinMHz = float64(min) / 1000
+		}
+		if max, err := readSysfsUint(filepath.Join(path, "cpufreq/scaling_max_freq")); err == nil {
+			freq.MaxMHz = float64(max) / 1000
+		}
+		freq.Governor, _ = readSysfsString(filepath.Join(path, "cpufreq/scaling_governor"))
+		freq.EnergyPerfPreference, _ = readSysfsString(filepath.Join(path, "cpufreq/energy_performance_preference"))
+
+		// Intel only, counts how often the core or package hit PROCHOT
+		if count, err := readSysfsUint(filepath.Join(path, "thermal_throttle/core_throttle_count")); err == nil {
+			freq.CoreThrottleCount = &count
+		}
+		if count, err := readSysfsUint(filepath.Join(path, "thermal_throttle/package_throttle_count")); err == nil {
+			freq.PackageThrottleCount = &count
+		}
+
+		freqs = append(freqs, freq)
+	}
+
+	sort.Slice(freqs, func(i, j int) bool { return freqs[i].CPU < freqs[j].CPU })
+	return freqs
+}
+
+// collectCPUFreqData reports per-core frequency, governor and throttle counters,
+// and sets CPUMHZ to the average current frequency. Returns false if cpufreq isn't available.
+func collectCPUFreqData(s *models.System) bool {
+	freqs := readCPUFreqs()
+	if len(freqs) == 0 {
+		return false
+	}
+
+	total := 0.0
+	for _, freq := range freqs {
+		total += freq.CurMHz
+		s.Custom[fmt.Sprintf("cpu_freq_%d", freq.CPU)] = fmt.Sprintf("%.0f MHz", freq.CurMHz)
+		s.Custom[fmt.Sprintf("cpu_freq_min_%d", freq.CPU)] = fmt.Sprintf("%.0f MHz", freq.MinMHz)
+		s.Custom[fmt.Sprintf("cpu_freq_max_%d", freq.CPU)] = fmt.Sprintf("%.0f MHz", freq.MaxMHz)
+		if freq.Governor != "" {
+			s.Custom[fmt.Sprintf("cpu_governor_%d", freq.CPU)] = freq.Governor
+		}
+		if freq.EnergyPerfPreference != "" {
+			s.Custom[fmt.Sprintf("cpu_epp_%d", freq.CPU)] = freq.EnergyPerfPreference
+		}
+		if freq.CoreThrottleCount != nil {
+			s.Custom[fmt.Sprintf("cpu_throttle_core_%d", freq.CPU)] = fmt.Sprintf("%d", *freq.CoreThrottleCount)
+		}
+		if freq.PackageThrottleCount != nil {
+			s.Custom[fmt.Sprintf("cpu_throttle_package_%d", freq.CPU)] = fmt.Sprintf("%d", *freq.PackageThrottleCount)
+		}
+	}
+
+	s.CPUMHZ = fmt.Sprintf("%.0f MHz", total/float64(len(freqs)))
+	return true
+}
@@ -0,0 +1,45 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReadCPUFreqs(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	freqs := readCPUFreqs()
+	require.Len(t, freqs, 2)
+
+	assert.Equal(t, 0, freqs[0].CPU)
+	assert.Equal(t, 3600.0, freqs[0].CurMHz)
+	assert.Equal(t, 800.0, freqs[0].MinMHz)
+	assert.Equal(t, 4800.0, freqs[0].MaxMHz)
+	assert.Equal(t, "powersave", freqs[0].Governor)
+	assert.Equal(t, "balance_performance", freqs[0].EnergyPerfPreference)
+	require.NotNil(t, freqs[0].CoreThrottleCount)
+	assert.Equal(t, uint64(12), *freqs[0].CoreThrottleCount)
+
+	assert.Equal(t, "schedutil", freqs[1].Governor)
+	assert.Empty(t, freqs[1].EnergyPerfPreference)
+	assert.Nil(t, freqs[1].PackageThrottleCount)
+}
+
+func TestCollectCPUFreqData(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	s := models.NewSystem()
+	require.True(t, collectCPUFreqData(s))
+	assert.Equal(t, "3000 MHz", s.CPUMHZ)
+	assert.Equal(t, "2400 MHz", s.Custom["cpu_freq_1"])
+	assert.Equal(t, "4800 MHz", s.Custom["cpu_freq_max_0"])
+	assert.Equal(t, "12", s.Custom["cpu_throttle_core_0"])
+	assert.NotContains(t, s.Custom, "cpu_throttle_core_1")
+
+	sysRoot = t.TempDir()
+	assert.False(t, collectCPUFreqData(models.NewSystem()))
+}
@@ -0,0 +1,158 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultGameProcesses start a gaming session unless the config lists its own processes.
+// Steam runs every game under "reaper", Proton and Wine games under the wine preloaders.
+// gamescope is left out, in SteamOS Game Mode it runs for as long as the device is in that
+// mode, so every game played would end up in one session. The games it runs are caught by reaper.
+var defaultGameProcesses = []string{"reaper", "wine-preloader", "wine64-preloader", "lutris-wrapper"}
+
+var (
+	gameProcesses = defaultGameProcesses
+
+	// The running session, empty when no game is running
+	gameSessionID    string
+	gameSessionName  string
+	gameSessionStart time.Time
+)
+
+// configureGames applies the game processes from the config
+func configureGames(processes []string) error {
+	gameProcesses = defaultGameProcesses
+	if len(processes) > 0 {
+		gameProcesses = make([]string, len(processes))
+		for i, process := range processes {
+			if process == "" {
+				return fmt.Errorf("game process names can't be empty")
+			}
+			gameProcesses[i] = strings.ToLower(process)
+		}
+	}
+	return nil
+}
+
+// processNames returns the names a process is known by: its comm, which the kernel
+// truncates to 15 characters, the base name of its first argument and of its executable.
+func processNames(pidDir string) []string {
+	var names []string
+	if comm, err := readSysfsString(filepath.Join(pidDir, "comm")); err == nil {
+		names = append(names, executableName(comm))
+	}
+	if args := processArgs(pidDir); len(args) > 0 {
+		names = append(names, executableName(args[0]))
+	}
+	if exe, err := os.Readlink(filepath.Join(pidDir, "exe")); err == nil {
+		names = append(names, executableName(exe))
+	}
+	return names
+}
+
+// processArgs returns the command line of a process
+func processArgs(pidDir string) []string {
+	cmdline, err := os.ReadFile(filepath.Join(pidDir, "cmdline"))
+	if err != nil || len(cmdline) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(cmdline), "\x00"), "\x00")
+}
+
+// executableSuffixes are dropped from executable names, e.g. "witcher3.exe" is "witcher3"
+var executableSuffixes = []string{".exe", ".sh", ".x86_64"}
+
+// executableName returns the lowercase base name of a Unix or Windows path without its suffix
+func executableName(path string) string {
+	name := strings.ToLower(filepath.Base(strings.ReplaceAll(path, `\`, "/")))
+	for _, suffix := range executableSuffixes {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
+
+// gameName names the game a matched process runs. Steam's reaper gets the game's
+// command after "--", Wine processes already carry the Windows executable as argv[0].
+func gameName(pidDir, matched string) string {
+	args := processArgs(pidDir)
+	for i, arg := range args {
+		if arg == "--" && i+1 < len(args) {
+			return executableName(args[i+1])
+		}
+	}
+	if len(args) > 0 {
+		return executableName(args[0])
+	}
+	return matched
+}
+
+// runningGames returns the names of the running games, ordered by PID
+func runningGames() []string {
+	pidDirs, _ := filepath.Glob(filepath.Join(procRoot, "[0-9]*"))
+	sort.Slice(pidDirs, func(i, j int) bool {
+		a, _ := strconv.Atoi(filepath.Base(pidDirs[i]))
+		b, _ := strconv.Atoi(filepath.Base(pidDirs[j]))
+		return a < b
+	})
+
+	var games []string
+	for _, pidDir := range pidDirs {
+		for _, name := range processNames(pidDir) {
+			if matchesGameProcess(name) {
+				games = append(games, gameName(pidDir, name))
+				break
+			}
+		}
+	}
+	return games
+}
+
+func matchesGameProcess(name string) bool {
+	for _, process := range gameProcesses {
+		if name == process {
+			return true
+		}
+	}
+	return false
+}
+
+// collectGameSession starts a session when a game process appears and ends it when the
+// last one exits. Every sample taken during a session is tagged with its ID.
+func collectGameSession(s *models.System) {
+	games := runningGames()
+	now := time.Now()
+
+	if len(games) == 0 {
+		if gameSessionID != "" {
+			duration := now.Sub(gameSessionStart).Round(time.Second)
+			s.Events = append(s.Events, models.NewEvent("game_stopped",
+				fmt.Sprintf("%s stopped after %s", gameSessionName, duration),
+				map[string]string{"session": gameSessionID, "game": gameSessionName, "duration": duration.String()}))
+			gameSessionID = ""
+		}
+		return
+	}
+
+	if gameSessionID == "" {
+		// Launchers start the game, so the newest process is the most specific
+		gameSessionName = games[len(games)-1]
+		gameSessionStart = now
+		gameSessionID = fmt.Sprintf("%d-%s", now.Unix(), gameSessionName)
+		s.Events = append(s.Events, models.NewEvent("game_started",
+			fmt.Sprintf("%s started", gameSessionName),
+			map[string]string{"session": gameSessionID, "game": gameSessionName}))
+	}
+
+	if s.Tags == nil {
+		s.Tags = make(map[string]string)
+	}
+	s.Tags["session"] = gameSessionID
+	s.Tags["game"] = gameSessionName
+}
@@ -0,0 +1,58 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRunningGames(t *testing.T) {
+	procRoot = "testdata/proc"
+	defer func() { procRoot = "/proc" }()
+	defer configureGames(nil)
+
+	// Steam's reaper runs Dota 2, Wine runs The Witcher 3, the Steam client itself is no game
+	assert.Equal(t, []string{"dota", "witcher3"}, runningGames())
+
+	require.NoError(t, configureGames([]string{"Dota2"}))
+	assert.Equal(t, []string{"dota2"}, runningGames())
+
+	require.NoError(t, configureGames([]string{"cs2"}))
+	assert.Empty(t, runningGames())
+
+	assert.Error(t, configureGames([]string{""}))
+}
+
+func TestCollectGameSession(t *testing.T) {
+	procRoot = "testdata/proc"
+	defer func() {
+		procRoot = "/proc"
+		gameSessionID = ""
+	}()
+	defer configureGames(nil)
+
+	s := &models.System{}
+	collectGameSession(s)
+	require.NotEmpty(t, gameSessionID)
+	assert.Equal(t, map[string]string{"session": gameSessionID, "game": "witcher3"}, s.Tags)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "game_started", s.Events[0].Type)
+	sessionID := gameSessionID
+
+	// Still running, same session
+	s = &models.System{}
+	collectGameSession(s)
+	assert.Equal(t, sessionID, s.Tags["session"])
+	assert.Empty(t, s.Events)
+
+	// The game exited
+	procRoot = "testdata/empty"
+	s = &models.System{}
+	collectGameSession(s)
+	assert.Empty(t, s.Tags)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "game_stopped", s.Events[0].Type)
+	assert.Equal(t, sessionID, s.Events[0].Details["session"])
+	assert.Empty(t, gameSessionID)
+}
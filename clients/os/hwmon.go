@@ -0,0 +1,166 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hwmonInputPattern matches sysfs attributes like temp1_input, fan2_input, in0_input and power1_average
+var hwmonInputPattern = regexp.MustCompile(`^(temp|fan|in|power)(\d+)_(input|average)$`)
+
+// hwmonScales converts raw hwmon units (millidegrees, RPM, millivolts, microwatts) to display units
+var hwmonScales = map[string]struct {
+	divisor float64
+	unit    string
+}{
+	"temp":  {1000, "°C"},
+	"fan":   {1, "RPM"},
+	"in":    {1000, "V"},
+	"power": {1000000, "W"},
+}
+
+// hwmonChip is one /sys/class/hwmon/hwmonN directory
+type hwmonChip struct {
+	path   string
+	name   string
+	device string // base name of the backing device, empty for virtual chips
+}
+
+// hwmonChipIDs gives every chip an ID that survives reboots. hwmonN numbering depends on
+// driver probe order, so chips are named by driver and, when several share a driver
+// (e.g. two NVMe drives), also by their device. Virtual chips without a device fall back
+// to their hwmonN directory, which at least keeps the IDs unique.
+func hwmonChipIDs(chips []hwmonChip) []string {
+	counts := make(map[string]int)
+	for _, chip := range chips {
+		counts[chip.name]++
+	}
+
+	ids := make([]string, len(chips))
+	for i, chip := range chips {
+		ids[i] = chip.name
+		if counts[chip.name] > 1 {
+			if chip.device != "" {
+				ids[i] = chip.name + "_" + chip.device
+			} else {
+				ids[i] = chip.name + "_" + filepath.Base(chip.path)
+			}
+		}
+	}
+	return ids
+}
+
+// readHwmonSensors enumerates every temperature, fan, voltage and power input under /sys/class/hwmon
+func readHwmonSensors() []models.Sensor {
+	paths, _ := filepath.Glob(filepath.Join(sysRoot, "class/hwmon/hwmon*"))
+
+	chips := []hwmonChip{}
+	for _, path := range paths {
+		name, err := readSysfsString(filepath.Join(path, "name"))
+		if err != nil {
+			continue
+		}
+		chip := hwmonChip{path: path, name: name}
+		if device, err := filepath.EvalSymlinks(filepath.Join(path, "device")); err == nil {
+			chip.device = filepath.Base(device)
+		}
+		chips = append(chips, chip)
+	}
+
+	// Sorted by chip, then kind, then numerically by input so temp10 follows temp9
+	type indexedSensor struct {
+		sensor models.Sensor
+		chipID string
+		index  int
+	}
+	found := []indexedSensor{}
+
+	for i, chipID := range hwmonChipIDs(chips) {
+		chip := chips[i]
+		files, _ := filepath.Glob(filepath.Join(chip.path, "*_*"))
+		for _, file := range files {
+			match := hwmonInputPattern.FindStringSubmatch(filepath.Base(file))
+			if match == nil {
+				continue
+			}
+			kind, input := match[1], match[1]+match[2]
+
+			// Prefer the instantaneous reading if a power input has both
+			if match[3] == "average" {
+				if _, err := os.Stat(filepath.Join(chip.path, input+"_input")); err == nil {
+					continue
+				}
+			}
+
+			raw, err := readSysfsString(file)
+			if err != nil {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				continue
+			}
+
+			scale := hwmonScales[kind]
+			sensor := models.Sensor{
+				ID:    chipID + "/" + input,
+				Chip:  chip.name,
+				Label: input,
+				Kind:  kind,
+				Value: value / scale.divisor,
+				Unit:  scale.unit,
+			}
+			if label, err := readSysfsString(filepath.Join(chip.path, input+"_label")); err == nil && label != "" {
+				sensor.Label = label
+			}
+			if max, err := readSysfsString(filepath.Join(chip.path, input+"_max")); err == nil {
+				if v, err := strconv.ParseFloat(max, 64); err == nil {
+					sensor.Max = v / scale.divisor
+				}
+			}
+			if crit, err := readSysfsString(filepath.Join(chip.path, input+"_crit")); err == nil {
+				if v, err := strconv.ParseFloat(crit, 64); err == nil {
+					sensor.Crit = v / scale.divisor
+				}
+			}
+			index, _ := strconv.Atoi(match[2])
+			found = append(found, indexedSensor{sensor: sensor, chipID: chipID, index: index})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.chipID != b.chipID {
+			return a.chipID < b.chipID
+		}
+		if a.sensor.Kind != b.sensor.Kind {
+			return a.sensor.Kind < b.sensor.Kind
+		}
+		return a.index < b.index
+	})
+
+	sensors := make([]models.Sensor, len(found))
+	for i, f := range found {
+		sensors[i] = f.sensor
+	}
+	return sensors
+}
+
+// collectHwmonData reports every hwmon sensor except those the user chose to ignore
+func collectHwmonData(s *models.System) {
+	for _, sensor := range classifySensors(readHwmonSensors()) {
+		if sensor.Role != SensorRoleIgnore {
+			s.Sensors = append(s.Sensors, sensor)
+		}
+	}
+}
+
+// sensorKey names a sensor by chip and label, e.g. "k10temp_tctl" or "nct6798_systin"
+func sensorKey(sensor models.Sensor) string {
+	return strings.ToLower(sensor.Chip + "_" + strings.ReplaceAll(sensor.Label, " ", "_"))
+}
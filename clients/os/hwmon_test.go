@@ -0,0 +1,67 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadHwmonSensors(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	sensors := readHwmonSensors()
+
+	ids := []string{}
+	byID := make(map[string]models.Sensor)
+	for _, sensor := range sensors {
+		ids = append(ids, sensor.ID)
+		byID[sensor.ID] = sensor
+	}
+	assert.Equal(t, []string{
+		"amdgpu/power1", "amdgpu/temp1",
+		"k10temp/temp1", "k10temp/temp3",
+		"nct6798/fan1", "nct6798/fan2", "nct6798/in0", "nct6798/temp1", "nct6798/temp2", "nct6798/temp10",
+		"nvme_nvme0/temp1", "nvme_nvme1/temp1",
+	}, ids)
+
+	assert.Equal(t, models.Sensor{ID: "k10temp/temp1", Chip: "k10temp", Label: "Tctl", Kind: "temp", Value: 65.25, Unit: "°C"}, byID["k10temp/temp1"])
+	assert.Equal(t, models.Sensor{ID: "nct6798/temp1", Chip: "nct6798", Label: "SYSTIN", Kind: "temp", Value: 38, Unit: "°C", Max: 80, Crit: 100}, byID["nct6798/temp1"])
+	assert.Equal(t, 1250.0, byID["nct6798/fan1"].Value)
+	assert.Equal(t, "fan2", byID["nct6798/fan2"].Label)
+	assert.Equal(t, 1.104, byID["nct6798/in0"].Value)
+	assert.Equal(t, "V", byID["nct6798/in0"].Unit)
+	assert.Equal(t, 35.0, byID["amdgpu/power1"].Value)
+	assert.Equal(t, "W", byID["amdgpu/power1"].Unit)
+	assert.Equal(t, 84.85, byID["nvme_nvme0/temp1"].Crit)
+}
+
+func TestHwmonChipIDs(t *testing.T) {
+	chips := []hwmonChip{
+		{path: "/sys/class/hwmon/hwmon0", name: "k10temp", device: "0000:00:18.3"},
+		{path: "/sys/class/hwmon/hwmon1", name: "nvme", device: "nvme0"},
+		{path: "/sys/class/hwmon/hwmon2", name: "nvme", device: "nvme1"},
+		// Two virtual chips of the same driver, e.g. ACPI thermal zones
+		{path: "/sys/class/hwmon/hwmon3", name: "acpitz"},
+		{path: "/sys/class/hwmon/hwmon4", name: "acpitz"},
+	}
+	assert.Equal(t, []string{"k10temp", "nvme_nvme0", "nvme_nvme1", "acpitz_hwmon3", "acpitz_hwmon4"}, hwmonChipIDs(chips))
+}
+
+func TestCollectTemperatureData(t *testing.T) {
+	if isARM() {
+		t.Skip("ARM reads thermal zones")
+	}
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	s := models.NewSystem()
+	collectTemperatureData(s)
+
+	// Tctl outranks the board's CPUTIN reading
+	assert.Equal(t, "65.25°C", s.CPUTemp)
+	// Only PCH_CHIP_TEMP matches a chipset pattern
+	assert.Equal(t, "51.00°C", s.AverageChipsetTemp)
+	assert.Equal(t, "51.00°C", s.Custom["sensor_nct6798_pch_chip_temp"])
+	assert.Len(t, s.Sensors, 12)
+}
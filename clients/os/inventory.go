@@ -0,0 +1,101 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/mem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// readInventoryDisks lists the physical block devices from /sys/block. Virtual devices
+// (loop, zram, device mapper, md) have no device directory and are skipped.
+func readInventoryDisks() []models.InventoryDisk {
+	paths, _ := filepath.Glob(filepath.Join(sysRoot, "block/*"))
+
+	disks := []models.InventoryDisk{}
+	for _, path := range paths {
+		device := filepath.Join(path, "device")
+		if _, err := os.Stat(device); err != nil {
+			continue
+		}
+
+		disk := models.InventoryDisk{Name: filepath.Base(path)}
+		// The size is always counted in 512 byte sectors, whatever the logical block size
+		if sectors, err := readSysfsUint(filepath.Join(path, "size")); err == nil {
+			disk.Size = sectors * 512
+		}
+		disk.Model, _ = readSysfsString(filepath.Join(device, "model"))
+		// NVMe controllers expose the serial, SATA and SCSI disks only a world wide identifier
+		if serial, err := readSysfsString(filepath.Join(device, "serial")); err == nil {
+			disk.Serial = serial
+		} else {
+			disk.Serial, _ = readSysfsString(filepath.Join(device, "wwid"))
+		}
+		rotational, _ := readSysfsString(filepath.Join(path, "queue/rotational"))
+		disk.Rotational = rotational == "1"
+
+		disks = append(disks, disk)
+	}
+	return disks
+}
+
+// readInventoryNICs lists the physical network interfaces from /sys/class/net
+func readInventoryNICs() []models.InventoryNIC {
+	paths, _ := filepath.Glob(filepath.Join(sysRoot, "class/net/*"))
+
+	nics := []models.InventoryNIC{}
+	for _, path := range paths {
+		// Virtual interfaces (lo, bridges, tunnels, containers) have no device
+		device := filepath.Join(path, "device")
+		if _, err := os.Stat(device); err != nil {
+			continue
+		}
+
+		nic := models.InventoryNIC{Name: filepath.Base(path)}
+		nic.MAC, _ = readSysfsString(filepath.Join(path, "address"))
+		if driver, err := os.Readlink(filepath.Join(device, "driver")); err == nil {
+			nic.Driver = filepath.Base(driver)
+		}
+		nics = append(nics, nic)
+	}
+	return nics
+}
+
+// LinuxInventory describes the host, its disks and network interfaces
+func LinuxInventory() (*models.Inventory, error) {
+	hostInfo, err := host.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &models.Inventory{
+		Hostname:     hostInfo.Hostname,
+		OS:           hostInfo.OS,
+		Distro:       strings.TrimSpace(hostInfo.Platform + " " + hostInfo.PlatformVersion),
+		Kernel:       hostInfo.KernelVersion,
+		Architecture: runtime.GOARCH,
+		Disks:        readInventoryDisks(),
+		NICs:         readInventoryNICs(),
+		AgentVersion: utils.AgentVersion(),
+	}
+	if hostInfo.KernelArch != "" {
+		inventory.Architecture = hostInfo.KernelArch
+	}
+
+	if cpuInfo, err := cpu.Info(); err == nil && len(cpuInfo) > 0 {
+		inventory.CPUModel = cpuInfo[0].ModelName
+		inventory.Microcode = cpuInfo[0].Microcode
+	}
+	inventory.CPUCores, _ = cpu.Counts(false)
+	inventory.CPUThreads, _ = cpu.Counts(true)
+
+	if memory, err := mem.VirtualMemory(); err == nil {
+		inventory.TotalRAM = memory.Total
+	}
+	return inventory, nil
+}
@@ -0,0 +1,30 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadInventoryDisks(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	// loop0 is virtual and skipped
+	assert.Equal(t, []models.InventoryDisk{
+		{Name: "nvme0n1", Model: "Samsung SSD 980 PRO 1TB", Serial: "S5GXNF0R123456A", Size: 1000204886016},
+		{Name: "sda", Model: "WDC WD40EFRX-68N", Serial: "t10.ATA     WDC WD40EFRX-68N32N0                    WD-WCC7K1234567", Size: 4000787030016, Rotational: true},
+	}, readInventoryDisks())
+}
+
+func TestReadInventoryNICs(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	// lo is virtual and skipped
+	assert.Equal(t, []models.InventoryNIC{
+		{Name: "eth0", MAC: "3c:7c:3f:aa:bb:01", Driver: "igc"},
+		{Name: "eth1", MAC: "3c:7c:3f:aa:bb:02"},
+		{Name: "wlan0", MAC: "a4:c3:f0:11:22:33", Driver: "iwlwifi"},
+	}, readInventoryNICs())
+}
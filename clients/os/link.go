@@ -0,0 +1,110 @@
+package os
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wirelessLinkMax is the scale most drivers report link quality on in /proc/net/wireless
+const wirelessLinkMax = 70
+
+// wirelessStat is one interface line of /proc/net/wireless
+type wirelessStat struct {
+	Link  float64
+	Level float64 // dBm
+	Noise float64 // dBm, 0 if the driver does not report it
+}
+
+// parseWirelessValue parses a quality value, which the kernel suffixes with '.' once updated
+func parseWirelessValue(field string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSuffix(field, "."), 64)
+}
+
+// parseWireless parses /proc/net/wireless, e.g.
+//
+//	Inter-| sta-|   Quality        |   Discarded packets               | Missed | WE
+//	 face | tus | link level noise |  nwid  crypt   frag  retry   misc | beacon | 22
+//	 wlan0: 0000   54.  -56.  -256        0      0      0      0     12        0
+func parseWireless(path string) map[string]wirelessStat {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	stats := make(map[string]wirelessStat)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		iface, values, found := strings.Cut(scanner.Text(), ":")
+		fields := strings.Fields(values)
+		if !found || len(fields) < 4 {
+			continue
+		}
+
+		link, err1 := parseWirelessValue(fields[1])
+		level, err2 := parseWirelessValue(fields[2])
+		noise, err3 := parseWirelessValue(fields[3])
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+
+		// Old drivers report dBm as an unsigned byte
+		if level > 0 {
+			level -= 256
+		}
+		if noise > 0 {
+			noise -= 256
+		}
+		if noise <= -256 {
+			noise = 0
+		}
+		stats[strings.TrimSpace(iface)] = wirelessStat{Link: link, Level: level, Noise: noise}
+	}
+	return stats
+}
+
+// collectLinkData reports the state of every physical network interface, with
+// signal and link quality for Wi-Fi and negotiated speed for wired interfaces
+func collectLinkData(s *models.System) {
+	wireless := parseWireless(filepath.Join(procRoot, "net/wireless"))
+
+	ifaces, _ := filepath.Glob(filepath.Join(sysRoot, "class/net/*"))
+	for _, iface := range ifaces {
+		// Virtual interfaces (lo, bridges, tunnels, containers) have no device
+		if _, err := os.Stat(filepath.Join(iface, "device")); err != nil {
+			continue
+		}
+		name := filepath.Base(iface)
+
+		if operstate, err := readSysfsString(filepath.Join(iface, "operstate")); err == nil {
+			s.Custom[fmt.Sprintf("net_%s_operstate", name)] = operstate
+		}
+
+		if _, err := os.Stat(filepath.Join(iface, "wireless")); err == nil {
+			stat, ok := wireless[name]
+			if !ok {
+				continue // Not associated
+			}
+			s.Custom[fmt.Sprintf("wifi_%s_link_quality", name)] = fmt.Sprintf("%.0f%%", min(stat.Link/wirelessLinkMax*100, 100))
+			s.Custom[fmt.Sprintf("wifi_%s_signal", name)] = fmt.Sprintf("%.0f dBm", stat.Level)
+			if stat.Noise != 0 {
+				s.Custom[fmt.Sprintf("wifi_%s_noise", name)] = fmt.Sprintf("%.0f dBm", stat.Noise)
+			}
+			continue
+		}
+
+		// Speed is -1 or unreadable while the link is down
+		speed, err := readSysfsString(filepath.Join(iface, "speed"))
+		if err != nil {
+			continue
+		}
+		if mbps, err := strconv.Atoi(speed); err == nil && mbps > 0 {
+			s.Custom[fmt.Sprintf("net_%s_speed", name)] = fmt.Sprintf("%d Mb/s", mbps)
+		}
+	}
+}
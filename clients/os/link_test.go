@@ -0,0 +1,40 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseWireless(t *testing.T) {
+	stats := parseWireless("testdata/proc/net/wireless")
+
+	assert.Equal(t, map[string]wirelessStat{
+		"wlan0": {Link: 54, Level: -56},
+		// Unsigned dBm from an old driver
+		"wlan1": {Link: 35, Level: -56, Noise: -95},
+	}, stats)
+
+	assert.Nil(t, parseWireless("testdata/proc/net/missing"))
+}
+
+func TestCollectLinkData(t *testing.T) {
+	procRoot = "testdata/proc"
+	sysRoot = "testdata/sys"
+	defer func() {
+		procRoot = "/proc"
+		sysRoot = "/sys"
+	}()
+
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectLinkData(s)
+
+	assert.Equal(t, map[string]interface{}{
+		"net_wlan0_operstate":     "up",
+		"wifi_wlan0_link_quality": "77%",
+		"wifi_wlan0_signal":       "-56 dBm",
+		"net_eth0_operstate":      "up",
+		"net_eth0_speed":          "1000 Mb/s",
+		"net_eth1_operstate":      "down",
+	}, s.Custom)
+}
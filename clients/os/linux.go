@@ -11,8 +11,9 @@ import (
 	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/shirou/gopsutil/v4/net"
 	"github.com/shirou/gopsutil/v4/process"
-	"github.com/shirou/gopsutil/v4/sensors"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,11 +26,30 @@ func Linux() (*models.System, error) {
 
 	collectTemperatureData(s)
 	collectNetworkData(s)
+	collectLinkData(s)
+	collectInterfaceEvents(s)
+	collectSocketData(s)
+	collectProbeData(s)
+	collectTextfileData(s)
+	collectPluginData(s)
+	collectUpdatesData(s)
+	collectAuthData(s)
+	collectMangoHudData(s)
+	collectGameSession(s)
 	collectCPUData(s)
+	collectThrottlingData(s)
+	collectPowerData(s)
+	collectBatteryData(s)
 	collectDiskData(s)
+	collectNetworkMountData(s)
+	collectRAIDData(s)
+	collectSMARTData(s)
 	collectSystemLoadData(s)
+	collectPressureData(s)
 	collectProcessData(s)
 	collectMemoryData(s)
+	collectMemoryBreakdownData(s)
+	collectOOMEvents(s)
 	collectSwapData(s)
 	collectHostData(s)
 
@@ -48,129 +68,127 @@ func readSysfsTemp(path string) (float64, error) {
 	return temp / 1000.0, err
 }
 
+// Common sensor patterns by manufacturer, used to guess the role of sensors the user didn't map
+var chipsetPatterns = []string{
+	"wmi",     // Gigabyte
+	"pch_",    // Intel PCH
+	"system",  // Common name
+	"board",   // Common name
+	"chipset", // Generic
+	"sbr",     // South Bridge
+	"nbr",     // North Bridge
+	"asus",    // ASUS
+	"msi",     // MSI
+	"asrock",  // ASRock
+	"mb",      // Motherboard
+}
+
+// CPU sensor patterns, most preferred first
+var cpuPatterns = []string{
+	"package", // CPU package
+	"tdie",    // AMD Tdie
+	"tctl",    // AMD Tctl
+	"cpu",     // Generic CPU
+	"core",    // Intel Core temps
+	"k10temp", // AMD K10
+}
+
 // collectTemperatureData gathers temperature information
 func collectTemperatureData(s *models.System) {
-	if isARM() {
-		// ARM-specific code - scan multiple thermal zones
-		cpuTemp := 0.0
-		chipsetTemps := []float64{}
-
-		// Try to find all thermal zones
-		for i := 0; i < 10; i++ {
-			zonePath := fmt.Sprintf("/sys/class/thermal/thermal_zone%d/temp", i)
-			typePath := fmt.Sprintf("/sys/class/thermal/thermal_zone%d/type", i)
-
-			if _, err := os.Stat(zonePath); os.IsNotExist(err) {
-				continue
-			}
+	collectHwmonData(s)
 
-			temp, err := readSysfsTemp(zonePath)
-			if err != nil {
-				continue
-			}
+	// ARM boards rarely expose useful hwmon chips, so use the thermal zones
+	// unless the user mapped a sensor
+	if isARM() && !hasConfiguredSensors(s.Sensors) {
+		collectThermalZoneData(s)
+		return
+	}
 
-			zoneType := "unknown"
-			if typeData, err := os.ReadFile(typePath); err == nil {
-				zoneType = strings.TrimSpace(string(typeData))
-			}
+	// For chipset/motherboard temperatures
+	chipsetTemps := []float64{}
+	cpuTemp := 0.0
 
-			if strings.Contains(strings.ToLower(zoneType), "cpu") || i == 0 {
-				cpuTemp = temp
-			} else {
-				chipsetTemps = append(chipsetTemps, temp)
-				s.Custom[fmt.Sprintf("thermal_zone_%d_%s", i, zoneType)] = fmt.Sprintf("%.2f°C", temp)
+	// Sensors the user mapped to the cpu role win over guesses, the hottest one is used.
+	// Otherwise pick the guessed CPU sensor matching the most preferred pattern.
+	foundConfiguredCPU := false
+	bestCPUPattern := len(cpuPatterns)
+	for _, sensor := range s.Sensors {
+		if sensor.Kind != "temp" || sensor.Role != SensorRoleCPU {
+			continue
+		}
+		if sensor.Configured {
+			if !foundConfiguredCPU || sensor.Value > cpuTemp {
+				cpuTemp = sensor.Value
 			}
+			foundConfiguredCPU = true
+		} else if rank := cpuPatternRank(sensor); !foundConfiguredCPU && rank < bestCPUPattern {
+			cpuTemp = sensor.Value
+			bestCPUPattern = rank
 		}
+	}
 
-		s.CPUTemp = fmt.Sprintf("%.2f°C", cpuTemp)
-
-		if len(chipsetTemps) > 0 {
-			sum := 0.0
-			for _, temp := range chipsetTemps {
-				sum += temp
-			}
-			s.AverageChipsetTemp = fmt.Sprintf("%.2f°C", sum/float64(len(chipsetTemps)))
+	for _, sensor := range s.Sensors {
+		if sensor.Kind != "temp" || sensor.Role != SensorRoleChipset {
+			continue
 		}
-	} else {
-		// Enhanced x86 approach for various motherboard manufacturers
-		temps, _ := sensors.SensorsTemperatures()
-
-		// For chipset/motherboard temperatures
-		chipsetTemps := []float64{}
-		cpuTemp := 0.0
-
-		// Track if we've found at least one sensor
-		foundCPUSensor := false
-
-		// Common sensor patterns by manufacturer
-		chipsetPatterns := []string{
-			"wmi",     // Gigabyte
-			"pch_",    // Intel PCH
-			"system",  // Common name
-			"board",   // Common name
-			"chipset", // Generic
-			"sbr",     // South Bridge
-			"nbr",     // North Bridge
-			"asus",    // ASUS
-			"msi",     // MSI
-			"asrock",  // ASRock
-			"mb",      // Motherboard
+		chipsetTemps = append(chipsetTemps, sensor.Value)
+		// Add individual sensor to custom fields
+		s.Custom[fmt.Sprintf("sensor_%s", sensorKey(sensor))] = fmt.Sprintf("%.2f°C", sensor.Value)
+	}
+
+	// Calculate average chipset temperature
+	if len(chipsetTemps) > 0 {
+		sum := 0.0
+		for _, temp := range chipsetTemps {
+			sum += temp
 		}
+		s.AverageChipsetTemp = fmt.Sprintf("%.2f°C", sum/float64(len(chipsetTemps)))
+	}
 
-		cpuPatterns := []string{
-			"tctl",    // AMD Tctl
-			"tdie",    // AMD Tdie
-			"core",    // Intel Core temps
-			"cpu",     // Generic CPU
-			"package", // CPU package
-			"k10temp", // AMD K10
+	// If we found a CPU temperature, use it
+	if foundConfiguredCPU || bestCPUPattern < len(cpuPatterns) {
+		s.CPUTemp = fmt.Sprintf("%.2f°C", cpuTemp)
+	}
+}
+
+// collectThermalZoneData reads temperatures from every thermal zone (for ARM devices)
+func collectThermalZoneData(s *models.System) {
+	cpuTemp := 0.0
+	chipsetTemps := []float64{}
+
+	zones, _ := filepath.Glob(filepath.Join(sysRoot, "class/thermal/thermal_zone*"))
+	for _, zone := range zones {
+		i, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(zone), "thermal_zone"))
+		if err != nil {
+			continue
 		}
 
-		// First pass: look for CPU temperature
-		for _, value := range temps {
-			sensorKey := strings.ToLower(value.SensorKey)
-
-			// Check for CPU temperature sensors
-			for _, pattern := range cpuPatterns {
-				if strings.Contains(sensorKey, pattern) {
-					cpuTemp = value.Temperature
-					foundCPUSensor = true
-					// Prefer core/package sensors if found
-					if strings.Contains(sensorKey, "core") ||
-						strings.Contains(sensorKey, "package") {
-						break
-					}
-				}
-			}
+		temp, err := readSysfsTemp(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
 		}
 
-		// Second pass: look for chipset temperatures
-		for _, value := range temps {
-			sensorKey := strings.ToLower(value.SensorKey)
-
-			for _, pattern := range chipsetPatterns {
-				if strings.Contains(sensorKey, pattern) {
-					chipsetTemps = append(chipsetTemps, value.Temperature)
-					// Add individual sensor to custom fields
-					s.Custom[fmt.Sprintf("sensor_%s", value.SensorKey)] = fmt.Sprintf("%.2f°C", value.Temperature)
-					break
-				}
-			}
+		zoneType := "unknown"
+		if typeData, err := readSysfsString(filepath.Join(zone, "type")); err == nil {
+			zoneType = typeData
 		}
 
-		// Calculate average chipset temperature
-		if len(chipsetTemps) > 0 {
-			sum := 0.0
-			for _, temp := range chipsetTemps {
-				sum += temp
-			}
-			s.AverageChipsetTemp = fmt.Sprintf("%.2f°C", sum/float64(len(chipsetTemps)))
+		if strings.Contains(strings.ToLower(zoneType), "cpu") || i == 0 {
+			cpuTemp = temp
+		} else {
+			chipsetTemps = append(chipsetTemps, temp)
+			s.Custom[fmt.Sprintf("thermal_zone_%d_%s", i, zoneType)] = fmt.Sprintf("%.2f°C", temp)
 		}
+	}
+
+	s.CPUTemp = fmt.Sprintf("%.2f°C", cpuTemp)
 
-		// If we found a CPU temperature, use it
-		if foundCPUSensor {
-			s.CPUTemp = fmt.Sprintf("%.2f°C", cpuTemp)
+	if len(chipsetTemps) > 0 {
+		sum := 0.0
+		for _, temp := range chipsetTemps {
+			sum += temp
 		}
+		s.AverageChipsetTemp = fmt.Sprintf("%.2f°C", sum/float64(len(chipsetTemps)))
 	}
 }
 
@@ -203,32 +221,12 @@ func collectCPUData(s *models.System) {
 		s.CPUUsage = fmt.Sprintf("%.2f%%", totalCPU[0])
 	}
 
-	// Get CPU frequency based on architecture
-	if isARM() {
-		// ARM-specific frequency reading
-		freqPath := "/sys/devices/system/cpu/cpu0/cpufreq/scaling_cur_freq"
-		if freqData, err := os.ReadFile(freqPath); err == nil {
-			var freq float64
-			fmt.Sscanf(string(freqData), "%f", &freq)
-			s.CPUMHZ = fmt.Sprintf("%.0f MHz", freq/1000) // Convert KHz to MHz
-		}
-	} else {
-		// x86 frequency calculation
-		freqs, err := cpu.Percent(100*time.Millisecond, true)
-		if err == nil && len(freqs) > 0 {
-			cpuInfo, _ := cpu.Info()
-			maxFreq := 0.0
-			if len(cpuInfo) > 0 {
-				maxFreq = cpuInfo[0].Mhz
-			}
-
-			currentFreq := 0.0
-			for _, f := range freqs {
-				currentFreq += (f / 100.0) * maxFreq
-			}
-			currentFreq /= float64(len(freqs))
-
-			s.CPUMHZ = fmt.Sprintf("%.0f MHz", currentFreq)
+	// Actual per-core frequencies, falling back to the frequency /proc/cpuinfo reports
+	// for the first core when there is no cpufreq driver (e.g. some VMs)
+	if !collectCPUFreqData(s) {
+		cpuInfo, err := cpu.Info()
+		if err == nil && len(cpuInfo) > 0 && cpuInfo[0].Mhz > 0 {
+			s.CPUMHZ = fmt.Sprintf("%.0f MHz", cpuInfo[0].Mhz)
 		}
 	}
 }
@@ -239,7 +237,8 @@ func collectDiskData(s *models.System) {
 	var totalDiskSpace, usedDiskSpace, freeDiskSpace uint64
 
 	for _, partition := range partitions {
-		// Skip pseudo filesystems
+		// Skip pseudo filesystems, and network filesystems as disk.Usage blocks on a dead
+		// server. Those are probed with a deadline by collectNetworkMountData.
 		if !strings.HasPrefix(partition.Fstype, "ext") &&
 			!strings.HasPrefix(partition.Fstype, "xfs") &&
 			!strings.HasPrefix(partition.Fstype, "btrfs") &&
@@ -310,4 +309,6 @@ func collectHostData(s *models.System) {
 	hours := int(uptimeDuration.Hours()) % 24
 	minutes := int(uptimeDuration.Minutes()) % 60
 	s.Uptime = fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	s.BootTime = int64(hostInfo.BootTime)
+	s.UptimeSeconds = hostInfo.Uptime
 }
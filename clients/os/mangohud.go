@@ -0,0 +1,210 @@
+package os
+
+import (
+	"bufio"
+	"bytes"
+	"device-chronicle-client/models"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mangohudActiveWindow is how recently a log must have been written to to count as a running game
+const mangohudActiveWindow = 30 * time.Second
+
+// mangohudLogName matches MangoHud's log file names, e.g. cs2_2024-05-01_20-15-42.csv
+var mangohudLogName = regexp.MustCompile(`^(.+)_\d{4}-\d{2}-\d{2}_\d{2}-\d{2}-\d{2}\.csv$`)
+
+var (
+	mangohudDirectory string
+	mangohudFile      string
+	mangohudOffset    int64
+	mangohudColumns   map[string]int
+)
+
+// configureMangoHud validates and applies the MangoHud log directory from the config
+func configureMangoHud(config *models.MangoHudConfig) error {
+	if config != nil && config.Directory == "" {
+		return fmt.Errorf("mangohud directory is required")
+	}
+
+	mangohudDirectory = ""
+	if config != nil {
+		mangohudDirectory = config.Directory
+	}
+	mangohudFile, mangohudOffset, mangohudColumns = "", 0, nil
+	return nil
+}
+
+// latestMangoHudLog returns the most recently written CSV log in dir
+func latestMangoHudLog(dir string) (string, time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", time.Time{}
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".csv" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latestTime) {
+			latest, latestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	return latest, latestTime
+}
+
+// readMangoHudRows reads the rows appended to a MangoHud log since offset. The log starts
+// with a system info header (os,cpu,gpu,... and its values) followed by the column header
+// (fps,frametime,cpu_load,...), columns are found by name as they differ between versions.
+// Only complete lines are read, the returned offset is where the next read continues.
+func readMangoHudRows(path string, offset int64, columns map[string]int) ([][]string, int64, map[string]int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset, columns, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, columns, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxMetricsSize))
+	if err != nil {
+		return nil, offset, columns, err
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset, columns, nil
+	}
+	data = data[:end+1]
+
+	var rows [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if columns == nil {
+			if fields[0] == "fps" {
+				columns = make(map[string]int, len(fields))
+				for i, name := range fields {
+					columns[strings.TrimSpace(name)] = i
+				}
+			}
+			continue
+		}
+		rows = append(rows, fields)
+	}
+	return rows, offset + int64(len(data)), columns, nil
+}
+
+// mangohudValues returns a column of the rows as numbers, skipping rows without it
+func mangohudValues(rows [][]string, columns map[string]int, name string) []float64 {
+	index, ok := columns[name]
+	if !ok {
+		return nil
+	}
+	values := make([]float64, 0, len(rows))
+	for _, row := range rows {
+		if index >= len(row) {
+			continue
+		}
+		if value, err := strconv.ParseFloat(strings.TrimSpace(row[index]), 64); err == nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// percentile returns the p-th percentile (0-100) of values using the nearest-rank method
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+func average(values []float64) float64 {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// mangohudMetrics summarizes the rows logged since the previous sample
+func mangohudMetrics(s *models.System, rows [][]string, columns map[string]int) {
+	if fps := mangohudValues(rows, columns, "fps"); len(fps) > 0 {
+		s.Custom["game_fps"] = fmt.Sprintf("%.1f fps", average(fps))
+	}
+	if frametimes := mangohudValues(rows, columns, "frametime"); len(frametimes) > 0 {
+		s.Custom["game_frametime_p50"] = fmt.Sprintf("%.2f ms", percentile(frametimes, 50))
+		s.Custom["game_frametime_p95"] = fmt.Sprintf("%.2f ms", percentile(frametimes, 95))
+		s.Custom["game_frametime_p99"] = fmt.Sprintf("%.2f ms", percentile(frametimes, 99))
+		if p99 := percentile(frametimes, 99); p99 > 0 {
+			s.Custom["game_fps_1_low"] = fmt.Sprintf("%.1f fps", 1000/p99)
+		}
+	}
+
+	// GPU readings, averaged over the rows
+	gpu := []struct {
+		column string
+		key    string
+		format string
+		scale  float64
+	}{
+		{"gpu_load", "gpu_load", "%.0f%%", 1},
+		{"gpu_temp", "gpu_temp", "%.0f°C", 1},
+		{"gpu_core_clock", "gpu_core_clock", "%.0f MHz", 1},
+		{"gpu_mem_clock", "gpu_mem_clock", "%.0f MHz", 1},
+		{"gpu_power", "gpu_power", "%.1f W", 1},
+		{"gpu_vram_used", "gpu_vram_used", "%.0f MB", 1024}, // Logged in GB
+	}
+	for _, field := range gpu {
+		if values := mangohudValues(rows, columns, field.column); len(values) > 0 {
+			s.Custom[field.key] = fmt.Sprintf(field.format, average(values)*field.scale)
+		}
+	}
+}
+
+// collectMangoHudData reports frame rate, frametime percentiles and GPU readings from the
+// rows MangoHud logged since the previous sample. Nothing is reported unless a game is logging.
+func collectMangoHudData(s *models.System) {
+	if mangohudDirectory == "" {
+		return
+	}
+
+	path, modTime := latestMangoHudLog(mangohudDirectory)
+	if path == "" || time.Since(modTime) > mangohudActiveWindow {
+		return
+	}
+
+	// A new log means a new game session
+	if path != mangohudFile {
+		mangohudFile, mangohudOffset, mangohudColumns = path, 0, nil
+	}
+
+	rows, offset, columns, err := readMangoHudRows(mangohudFile, mangohudOffset, mangohudColumns)
+	if err != nil {
+		return
+	}
+	mangohudOffset, mangohudColumns = offset, columns
+	if len(rows) == 0 {
+		return
+	}
+
+	if match := mangohudLogName.FindStringSubmatch(filepath.Base(path)); match != nil {
+		s.Custom["game_name"] = match[1]
+	}
+	mangohudMetrics(s, rows, columns)
+}
@@ -0,0 +1,84 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadMangoHudRows(t *testing.T) {
+	rows, offset, columns, err := readMangoHudRows("testdata/mangohud/cs2_2024-05-01_20-15-42.csv", 0, nil)
+	require.NoError(t, err)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, 0, columns["fps"])
+	assert.Equal(t, 9, columns["gpu_vram_used"])
+
+	// Nothing new since the last read
+	rows, next, _, err := readMangoHudRows("testdata/mangohud/cs2_2024-05-01_20-15-42.csv", offset, columns)
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+	assert.Equal(t, offset, next)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{7, 1, 5, 3, 9, 2, 8, 4, 6, 10}
+	assert.Equal(t, 5.0, percentile(values, 50))
+	assert.Equal(t, 10.0, percentile(values, 95))
+	assert.Equal(t, 1.0, percentile(values, 1))
+	assert.Equal(t, 7.0, percentile([]float64{7}, 99))
+}
+
+func TestCollectMangoHudData(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, configureMangoHud(&models.MangoHudConfig{Directory: dir}))
+	defer configureMangoHud(nil)
+
+	collect := func() *models.System {
+		s := &models.System{Custom: make(map[string]interface{})}
+		collectMangoHudData(s)
+		return s
+	}
+
+	// No game running
+	assert.Empty(t, collect().Custom)
+
+	// MangoHud is logging, the last line is still being written
+	fixture, err := os.ReadFile("testdata/mangohud/cs2_2024-05-01_20-15-42.csv")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "cs2_2024-05-01_20-15-42.csv")
+	require.NoError(t, os.WriteFile(path, append(fixture, "160.0,6.2"...), 0644))
+
+	s := collect()
+	assert.Equal(t, "cs2", s.Custom["game_name"])
+	assert.Equal(t, "121.2 fps", s.Custom["game_fps"])
+	assert.Equal(t, "6.90 ms", s.Custom["game_frametime_p50"])
+	assert.Equal(t, "20.00 ms", s.Custom["game_frametime_p99"])
+	assert.Equal(t, "50.0 fps", s.Custom["game_fps_1_low"])
+	assert.Equal(t, "98%", s.Custom["gpu_load"])
+	assert.Equal(t, "72°C", s.Custom["gpu_temp"])
+	assert.Equal(t, "5760 MB", s.Custom["gpu_vram_used"])
+	assert.Equal(t, "123.2 W", s.Custom["gpu_power"])
+
+	// Only the rows written since the previous sample count
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(",45,55,96,68,70,2450,1750,5.5,120,9.2,0,3.1,500000000\n")
+	require.NoError(t, err)
+	file.Close()
+
+	s = collect()
+	assert.Equal(t, "160.0 fps", s.Custom["game_fps"])
+	assert.Equal(t, "6.20 ms", s.Custom["game_frametime_p99"])
+
+	// Nothing new logged
+	assert.Empty(t, collect().Custom)
+
+	// The game stopped a while ago
+	old := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(path, old, old))
+	assert.Empty(t, collect().Custom)
+}
@@ -0,0 +1,133 @@
+package os
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	prevVmstat     map[string]uint64
+	prevVmstatTime time.Time
+)
+
+// parseMeminfo parses /proc/meminfo. Values suffixed with "kB" are converted
+// to bytes, unitless values (such as HugePages_Total) are returned as-is.
+func parseMeminfo(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	meminfo := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, rest, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+		meminfo[key] = value
+	}
+	return meminfo, scanner.Err()
+}
+
+// parseVmstat parses the "name value" counters of /proc/vmstat
+func parseVmstat(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	vmstat := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		vmstat[fields[0]] = value
+	}
+	return vmstat, scanner.Err()
+}
+
+// collectMemoryBreakdownData reports where RAM is going according to /proc/meminfo,
+// plus paging rates from /proc/vmstat. All sizes use MB so the dashboard can stack them.
+func collectMemoryBreakdownData(s *models.System) {
+	if meminfo, err := parseMeminfo(filepath.Join(procRoot, "meminfo")); err == nil {
+		total := meminfo["MemTotal"]
+		free := meminfo["MemFree"]
+		buffers := meminfo["Buffers"]
+		cached := meminfo["Cached"]
+		slab := meminfo["Slab"]
+
+		// Memory held by processes, i.e. everything the kernel can't easily give back
+		apps := uint64(0)
+		if total > free+buffers+cached+slab {
+			apps = total - free - buffers - cached - slab
+		}
+
+		s.Custom["mem_available"] = utils.FormatMegabytes(meminfo["MemAvailable"])
+		s.Custom["mem_apps"] = utils.FormatMegabytes(apps)
+		s.Custom["mem_free"] = utils.FormatMegabytes(free)
+		s.Custom["mem_buffers"] = utils.FormatMegabytes(buffers)
+		s.Custom["mem_cached"] = utils.FormatMegabytes(cached)
+		s.Custom["mem_shared"] = utils.FormatMegabytes(meminfo["Shmem"])
+		s.Custom["mem_dirty"] = utils.FormatMegabytes(meminfo["Dirty"])
+		s.Custom["mem_writeback"] = utils.FormatMegabytes(meminfo["Writeback"])
+		s.Custom["mem_slab"] = utils.FormatMegabytes(slab)
+		s.Custom["mem_slab_reclaimable"] = utils.FormatMegabytes(meminfo["SReclaimable"])
+
+		// HugePages_* are page counts, Hugepagesize is already in bytes
+		if pageSize := meminfo["Hugepagesize"]; pageSize > 0 {
+			s.Custom["mem_hugepages_total"] = utils.FormatMegabytes(meminfo["HugePages_Total"] * pageSize)
+			s.Custom["mem_hugepages_free"] = utils.FormatMegabytes(meminfo["HugePages_Free"] * pageSize)
+		}
+	}
+
+	now := time.Now()
+	vmstat, err := parseVmstat(filepath.Join(procRoot, "vmstat"))
+	if err != nil {
+		return
+	}
+
+	if prevVmstat != nil {
+		if elapsed := now.Sub(prevVmstatTime).Seconds(); elapsed > 0 {
+			rates := map[string]string{
+				"pswpin":     "mem_swap_in_rate",
+				"pswpout":    "mem_swap_out_rate",
+				"pgmajfault": "mem_major_fault_rate",
+			}
+			for counter, key := range rates {
+				if vmstat[counter] >= prevVmstat[counter] {
+					s.Custom[key] = fmt.Sprintf("%.2f/s", float64(vmstat[counter]-prevVmstat[counter])/elapsed)
+				}
+			}
+		}
+	}
+
+	prevVmstat = vmstat
+	prevVmstatTime = now
+}
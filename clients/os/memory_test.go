@@ -0,0 +1,52 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	meminfo, err := parseMeminfo("testdata/proc/meminfo")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(16384000*1024), meminfo["MemTotal"])
+	assert.Equal(t, uint64(4), meminfo["HugePages_Total"])
+	assert.Equal(t, uint64(2048*1024), meminfo["Hugepagesize"])
+}
+
+func TestParseVmstat(t *testing.T) {
+	vmstat, err := parseVmstat("testdata/proc/vmstat")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), vmstat["pswpin"])
+	assert.Equal(t, uint64(5000), vmstat["pgmajfault"])
+}
+
+func TestCollectMemoryBreakdownData(t *testing.T) {
+	procRoot = "testdata/proc"
+	defer func() {
+		procRoot = "/proc"
+		prevVmstat = nil
+	}()
+
+	// Pretend the previous sample was two seconds ago
+	prevVmstat = map[string]uint64{"pswpin": 900, "pswpout": 2000, "pgmajfault": 4000}
+	prevVmstatTime = time.Now().Add(-2 * time.Second)
+
+	s := models.NewSystem()
+	collectMemoryBreakdownData(s)
+
+	assert.Equal(t, "9000.0 MB", s.Custom["mem_available"])
+	assert.Equal(t, "500.0 MB", s.Custom["mem_buffers"])
+	assert.Equal(t, "6000.0 MB", s.Custom["mem_cached"])
+	assert.Equal(t, "400.0 MB", s.Custom["mem_shared"])
+	assert.Equal(t, "1000.0 MB", s.Custom["mem_slab"])
+	// 16000 - 2000 free - 500 buffers - 6000 cached - 1000 slab
+	assert.Equal(t, "6500.0 MB", s.Custom["mem_apps"])
+	assert.Equal(t, "8.0 MB", s.Custom["mem_hugepages_total"])
+	assert.Equal(t, "4.0 MB", s.Custom["mem_hugepages_free"])
+	assert.Contains(t, s.Custom, "mem_swap_in_rate")
+	assert.Contains(t, s.Custom["mem_swap_out_rate"], "0.00")
+	assert.Equal(t, uint64(5000), prevVmstat["pgmajfault"])
+}
@@ -0,0 +1,137 @@
+package os
+
+import (
+	"device-chronicle-client/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parsePromLabels parses the label set of a Prometheus sample starting after the
+// opening brace, returning the labels and the rest of the line after the closing brace
+func parsePromLabels(line string) (map[string]string, string, error) {
+	labels := make(map[string]string)
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(line, "}") {
+			return labels, line[1:], nil
+		}
+
+		name, rest, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		rest = strings.TrimLeft(rest, " \t")
+		if !found || name == "" || !strings.HasPrefix(rest, `"`) {
+			return nil, "", errors.New("invalid label")
+		}
+
+		// Quoted value with \\, \" and \n escapes
+		var value strings.Builder
+		i := 1
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+				if rest[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(rest[i])
+		}
+		if i >= len(rest) {
+			return nil, "", errors.New("unterminated label value")
+		}
+		labels[name] = value.String()
+
+		line = strings.TrimLeft(rest[i+1:], " \t")
+		line = strings.TrimPrefix(line, ",")
+	}
+}
+
+// promMetricKey turns a sample into a metric key, label values are appended in label name order,
+// e.g. backup_age_seconds{job="nas"} becomes backup_age_seconds_nas
+func promMetricKey(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	parts := []string{name}
+	for _, label := range names {
+		parts = append(parts, labels[label])
+	}
+	return utils.SanitizeMetricKey(strings.Join(parts, "_"))
+}
+
+// parsePromText parses metrics in the Prometheus text exposition format. Any invalid line
+// rejects the whole input, like node_exporter does for textfiles. NaN and infinite values
+// are skipped as they can't be sent as JSON.
+func parsePromText(data []byte) (map[string]interface{}, error) {
+	metrics := make(map[string]interface{})
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		end := strings.IndexFunc(line, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':')
+		})
+		if end <= 0 {
+			return nil, fmt.Errorf("line %d: invalid metric name", i+1)
+		}
+		name, rest := line[:end], line[end:]
+
+		var labels map[string]string
+		if strings.HasPrefix(rest, "{") {
+			var err error
+			if labels, rest, err = parsePromLabels(rest[1:]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+
+		// Value, optionally followed by a timestamp
+		fields := strings.Fields(rest)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a value", i+1)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value %q", i+1, fields[0])
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		metrics[promMetricKey(name, labels)] = value
+	}
+	return metrics, nil
+}
+
+// parseJSONMetrics parses a JSON object of metrics. Nested objects are flattened with
+// underscores, numbers, strings and booleans are kept, arrays and nulls are skipped.
+func parseJSONMetrics(data []byte) (map[string]interface{}, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[string]interface{})
+	var flatten func(prefix string, object map[string]interface{})
+	flatten = func(prefix string, object map[string]interface{}) {
+		for key, value := range object {
+			key = prefix + utils.SanitizeMetricKey(key)
+			switch value := value.(type) {
+			case float64, string, bool:
+				metrics[key] = value
+			case map[string]interface{}:
+				flatten(key+"_", value)
+			}
+		}
+	}
+	flatten("", object)
+	return metrics, nil
+}
@@ -0,0 +1,52 @@
+package os
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParsePromText(t *testing.T) {
+	metrics, err := parsePromText([]byte(`# HELP game_fps Frames per second
+# TYPE game_fps gauge
+game_fps{game="cs2",gpu="0"} 143
+game_fps{ game = "dota 2" , } 90 1700000000000
+escaped{path="C:\\games\\\"x\""} 1
+up +Inf
+load_1 0.5
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"game_fps_cs2_0":       143.0,
+		"game_fps_dota_2":      90.0,
+		"escaped_c__games__x_": 1.0,
+		"load_1":               0.5,
+	}, metrics)
+
+	for _, invalid := range []string{
+		"fps",
+		"fps abc",
+		"fps 1 2 3",
+		`fps{game="cs2} 1`,
+		`fps{game=cs2} 1`,
+		"{game=\"cs2\"} 1",
+	} {
+		_, err := parsePromText([]byte("ok 1\n" + invalid))
+		assert.ErrorContains(t, err, "line 2", invalid)
+	}
+}
+
+func TestParseJSONMetrics(t *testing.T) {
+	metrics, err := parseJSONMetrics([]byte(`{"fps": 143, "Title": "cs2", "vsync": false, "gpu": {"load": 97.5, "vram": {"used_mb": 6100}}, "list": [1], "none": null}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"fps":              143.0,
+		"title":            "cs2",
+		"vsync":            false,
+		"gpu_load":         97.5,
+		"gpu_vram_used_mb": 6100.0,
+	}, metrics)
+
+	_, err = parseJSONMetrics([]byte(`[1, 2]`))
+	assert.Error(t, err)
+}
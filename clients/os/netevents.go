@@ -0,0 +1,189 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// interfaceState is what is tracked of a network interface between samples
+type interfaceState struct {
+	Operstate      string
+	Carrier        bool
+	CarrierChanges uint64
+	Addrs          []string // Sorted, in CIDR notation
+}
+
+// up reports whether the interface can pass traffic. Tunnels such as WireGuard
+// report their operstate as "unknown" but have a carrier while they are up.
+func (state interfaceState) up() bool {
+	return state.Operstate == "up" || (state.Operstate == "unknown" && state.Carrier)
+}
+
+// vpnInterfacePrefixes name the virtual interfaces of VPNs, which are tracked although they
+// have no device. Other virtual interfaces come and go with containers and VMs.
+var vpnInterfacePrefixes = []string{"wg", "tun", "ppp", "tailscale", "zt"}
+
+var (
+	// interfaceAddrs returns the addresses of an interface, replaced in tests
+	interfaceAddrs = systemInterfaceAddrs
+
+	// The interfaces of the previous sample, nil before the first one
+	prevInterfaces map[string]interfaceState
+)
+
+// systemInterfaceAddrs returns the addresses of an interface except IPv6 link-local
+// ones, which every interface has and which never carry a change worth reporting
+func systemInterfaceAddrs(name string) []string {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsLinkLocalUnicast() && ipNet.IP.To4() == nil {
+			continue
+		}
+		result = append(result, addr.String())
+	}
+	sort.Strings(result)
+	return result
+}
+
+// isVPNInterface reports whether a virtual interface belongs to a VPN
+func isVPNInterface(name string) bool {
+	for _, prefix := range vpnInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// readInterfaceStates reads the state of the physical network interfaces and of VPNs, as VPNs
+// coming and going are worth reporting. Other virtual interfaces such as loopback, bridges and
+// the veth pairs of containers are skipped, Docker and libvirt create and remove them all the time.
+func readInterfaceStates() map[string]interfaceState {
+	ifaces, _ := filepath.Glob(filepath.Join(sysRoot, "class/net/*"))
+
+	states := make(map[string]interfaceState, len(ifaces))
+	for _, iface := range ifaces {
+		name := filepath.Base(iface)
+		if _, err := os.Stat(filepath.Join(iface, "device")); err != nil && !isVPNInterface(name) {
+			continue
+		}
+
+		state := interfaceState{Addrs: interfaceAddrs(name)}
+		state.Operstate, _ = readSysfsString(filepath.Join(iface, "operstate"))
+		// Reading the carrier fails while the interface is administratively down
+		carrier, _ := readSysfsString(filepath.Join(iface, "carrier"))
+		state.Carrier = carrier == "1"
+		state.CarrierChanges, _ = readSysfsUint(filepath.Join(iface, "carrier_changes"))
+		states[name] = state
+	}
+	return states
+}
+
+// addrChanges lists the addresses added to and removed from an interface
+func addrChanges(previous, current []string) (added, removed []string) {
+	previousSet := make(map[string]bool, len(previous))
+	for _, addr := range previous {
+		previousSet[addr] = true
+	}
+	currentSet := make(map[string]bool, len(current))
+	for _, addr := range current {
+		currentSet[addr] = true
+		if !previousSet[addr] {
+			added = append(added, addr)
+		}
+	}
+	for _, addr := range previous {
+		if !currentSet[addr] {
+			removed = append(removed, addr)
+		}
+	}
+	return added, removed
+}
+
+// diffInterfaces returns the events for the changes between two samples of the interfaces:
+// links going down or up, links that flapped in between samples and changed addresses
+func diffInterfaces(previous, current map[string]interfaceState) []models.Event {
+	names := make([]string, 0, len(previous)+len(current))
+	for name := range previous {
+		names = append(names, name)
+	}
+	for name := range current {
+		if _, ok := previous[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var events []models.Event
+	for _, name := range names {
+		before, existed := previous[name]
+		after, exists := current[name]
+		wasUp := existed && before.up()
+		isUp := exists && after.up()
+
+		switch {
+		case wasUp && !isUp:
+			message := fmt.Sprintf("%s went down", name)
+			details := map[string]string{"interface": name, "operstate": after.Operstate}
+			if !exists {
+				message = fmt.Sprintf("%s was removed", name)
+				details["removed"] = "true"
+			}
+			events = append(events, models.NewEvent("link_down", message, details))
+
+		case !wasUp && isUp:
+			events = append(events, models.NewEvent("link_up", fmt.Sprintf("%s came up", name),
+				map[string]string{"interface": name, "addresses": strings.Join(after.Addrs, ",")}))
+
+		case existed && exists:
+			// Every carrier change is counted, two or more with the same state on both
+			// samples mean the link went down and came back in between
+			if after.CarrierChanges >= before.CarrierChanges+2 {
+				flaps := (after.CarrierChanges - before.CarrierChanges) / 2
+				events = append(events, models.NewEvent("link_flap",
+					fmt.Sprintf("%s lost its link %d times since the last sample", name, flaps),
+					map[string]string{"interface": name, "flaps": fmt.Sprint(flaps)}))
+			}
+
+			if isUp && !reflect.DeepEqual(before.Addrs, after.Addrs) {
+				added, removed := addrChanges(before.Addrs, after.Addrs)
+				var changes []string
+				for _, addr := range added {
+					changes = append(changes, "+"+addr)
+				}
+				for _, addr := range removed {
+					changes = append(changes, "-"+addr)
+				}
+				events = append(events, models.NewEvent("ip_changed",
+					fmt.Sprintf("%s addresses changed: %s", name, strings.Join(changes, " ")),
+					map[string]string{"interface": name, "added": strings.Join(added, ","), "removed": strings.Join(removed, ",")}))
+			}
+		}
+	}
+	return events
+}
+
+// collectInterfaceEvents reports network interfaces going down or up, flapping, and address
+// changes such as DHCP renewals or VPNs connecting, since the previous sample
+func collectInterfaceEvents(s *models.System) {
+	current := readInterfaceStates()
+	if prevInterfaces != nil {
+		s.Events = append(s.Events, diffInterfaces(prevInterfaces, current)...)
+	}
+	prevInterfaces = current
+}
@@ -0,0 +1,93 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestReadInterfaceStates(t *testing.T) {
+	sysRoot = "testdata/sys"
+	interfaceAddrs = func(name string) []string {
+		if name == "eth0" {
+			return []string{"192.168.1.10/24"}
+		}
+		return nil
+	}
+	defer func() {
+		sysRoot = "/sys"
+		interfaceAddrs = systemInterfaceAddrs
+	}()
+
+	// Bridges, veth pairs and docker0 are skipped, the wg0 VPN is tracked
+	states := readInterfaceStates()
+	assert.Equal(t, map[string]interfaceState{
+		"eth0":  {Operstate: "up", Carrier: true, CarrierChanges: 4, Addrs: []string{"192.168.1.10/24"}},
+		"eth1":  {Operstate: "down", CarrierChanges: 1},
+		"wlan0": {Operstate: "up", Carrier: true, CarrierChanges: 2},
+		"wg0":   {Operstate: "unknown", Carrier: true},
+	}, states)
+	assert.True(t, states["wg0"].up())
+	assert.False(t, states["eth1"].up())
+}
+
+func TestDiffInterfaces(t *testing.T) {
+	previous := map[string]interfaceState{
+		"eth0":  {Operstate: "up", Carrier: true, CarrierChanges: 4, Addrs: []string{"192.168.1.10/24"}},
+		"eth1":  {Operstate: "down", CarrierChanges: 1},
+		"wlan0": {Operstate: "up", Carrier: true, CarrierChanges: 2, Addrs: []string{"10.0.0.5/24"}},
+		"wg0":   {Operstate: "unknown", Carrier: true, Addrs: []string{"10.8.0.2/32"}},
+	}
+	current := map[string]interfaceState{
+		// DHCP handed out a new address
+		"eth0": {Operstate: "up", Carrier: true, CarrierChanges: 4, Addrs: []string{"192.168.1.20/24"}},
+		"eth1": {Operstate: "up", Carrier: true, CarrierChanges: 2},
+		// Dropped and reassociated twice between samples
+		"wlan0": {Operstate: "up", Carrier: true, CarrierChanges: 6, Addrs: []string{"10.0.0.5/24"}},
+		// The VPN disconnected
+	}
+
+	events := diffInterfaces(previous, current)
+	messages := make([]string, len(events))
+	types := make([]string, len(events))
+	for i, event := range events {
+		messages[i] = event.Message
+		types[i] = event.Type
+	}
+	assert.Equal(t, []string{"ip_changed", "link_up", "link_down", "link_flap"}, types)
+	assert.Equal(t, []string{
+		"eth0 addresses changed: +192.168.1.20/24 -192.168.1.10/24",
+		"eth1 came up",
+		"wg0 was removed",
+		"wlan0 lost its link 2 times since the last sample",
+	}, messages)
+	assert.Equal(t, map[string]string{"interface": "eth0", "added": "192.168.1.20/24", "removed": "192.168.1.10/24"}, events[0].Details)
+	assert.Equal(t, "true", events[2].Details["removed"])
+	assert.Equal(t, "2", events[3].Details["flaps"])
+
+	// Nothing changed
+	assert.Empty(t, diffInterfaces(current, current))
+}
+
+func TestCollectInterfaceEvents(t *testing.T) {
+	sysRoot = "testdata/sys"
+	interfaceAddrs = func(string) []string { return nil }
+	defer func() {
+		sysRoot = "/sys"
+		interfaceAddrs = systemInterfaceAddrs
+		prevInterfaces = nil
+	}()
+
+	// The first sample only records the state
+	s := &models.System{}
+	collectInterfaceEvents(s)
+	assert.Empty(t, s.Events)
+	require.Contains(t, prevInterfaces, "eth0")
+
+	prevInterfaces["eth1"] = interfaceState{Operstate: "up", Carrier: true, CarrierChanges: 0}
+	collectInterfaceEvents(s)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "link_down", s.Events[0].Type)
+	assert.Equal(t, "eth1 went down", s.Events[0].Message)
+}
@@ -0,0 +1,222 @@
+package os
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"errors"
+	"fmt"
+	"github.com/shirou/gopsutil/v4/disk"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Health of a network mount
+const (
+	MountHealthy     = "healthy"
+	MountUnreachable = "unreachable" // The filesystem didn't answer in time, the server or the network hangs
+	MountStale       = "stale"       // Stale file handle (ESTALE), e.g. the export was removed or recreated on the server
+	MountError       = "error"       // The filesystem answered with another error
+)
+
+// mountProbeTimeout is how long a sample waits for a network filesystem to answer, shortened in tests
+var mountProbeTimeout = 2 * time.Second
+
+// networkFilesystems are the filesystem types whose calls may block on the network
+var networkFilesystems = map[string]bool{
+	"nfs":            true,
+	"nfs4":           true,
+	"cifs":           true,
+	"smb3":           true,
+	"smbfs":          true,
+	"ceph":           true,
+	"9p":             true,
+	"davfs":          true,
+	"glusterfs":      true,
+	"fuse.glusterfs": true,
+	"fuse.sshfs":     true,
+	"fuse.rclone":    true,
+}
+
+// networkMount is a mounted network filesystem
+type networkMount struct {
+	Source     string // e.g. nas:/export/media or //nas/share
+	Mountpoint string
+	Fstype     string
+}
+
+// mountProbe is a statfs call on a network mount, running in its own goroutine
+type mountProbe struct {
+	start time.Time
+	done  chan struct{} // Closed once the call returned, end, usage and err are set then
+	end   time.Time
+	usage *disk.UsageStat
+	err   error
+}
+
+var (
+	// mountUsage asks a filesystem for its usage, replaced in tests
+	mountUsage = disk.Usage
+
+	// Probes that didn't return yet by mountpoint. A call blocked on a dead server can't
+	// be cancelled, so no other is started for the mount until it returns.
+	hungMountProbes = map[string]*mountProbe{}
+
+	// Whether each mount was healthy in the previous sample
+	prevMountHealthy = map[string]bool{}
+)
+
+// unescapeMountField decodes the octal escapes /proc/mounts uses for spaces, tabs,
+// newlines and backslashes in mountpoints
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var result strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+4 <= len(field) {
+			if value, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				result.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		result.WriteByte(field[i])
+	}
+	return result.String()
+}
+
+// readNetworkMounts lists the mounted network filesystems from /proc/mounts
+func readNetworkMounts() []networkMount {
+	file, err := os.Open(filepath.Join(procRoot, "mounts"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var mounts []networkMount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !networkFilesystems[fields[2]] {
+			continue
+		}
+		mounts = append(mounts, networkMount{
+			Source:     unescapeMountField(fields[0]),
+			Mountpoint: unescapeMountField(fields[1]),
+			Fstype:     fields[2],
+		})
+	}
+	return mounts
+}
+
+// startMountProbe asks a mount for its usage in a new goroutine
+func startMountProbe(mountpoint string, now time.Time) *mountProbe {
+	probe := &mountProbe{start: now, done: make(chan struct{})}
+	go func() {
+		probe.usage, probe.err = mountUsage(mountpoint)
+		probe.end = time.Now()
+		close(probe.done)
+	}()
+	return probe
+}
+
+// collectNetworkMountData probes every network mount in parallel and reports whether it is
+// healthy, unreachable, stale or failing with its response time. A sample waits at most
+// mountProbeTimeout for new probes, a mount whose probe hangs from an earlier sample is
+// reported unreachable right away until that probe returns.
+func collectNetworkMountData(s *models.System) {
+	mounts := readNetworkMounts()
+	now := time.Now()
+	deadline := time.NewTimer(mountProbeTimeout)
+	defer deadline.Stop()
+
+	probes := make(map[string]*mountProbe, len(mounts))
+	started := make(map[string]bool, len(mounts))
+	for _, mount := range mounts {
+		if probe, hung := hungMountProbes[mount.Mountpoint]; hung {
+			select {
+			case <-probe.done:
+				delete(hungMountProbes, mount.Mountpoint)
+			default:
+				probes[mount.Mountpoint] = probe
+				continue
+			}
+		}
+		probes[mount.Mountpoint] = startMountProbe(mount.Mountpoint, now)
+		started[mount.Mountpoint] = true
+	}
+
+	timedOut := false
+	mounted := make(map[string]bool, len(mounts))
+	for _, mount := range mounts {
+		mounted[mount.Mountpoint] = true
+		probe := probes[mount.Mountpoint]
+		if started[mount.Mountpoint] && !timedOut {
+			select {
+			case <-probe.done:
+			case <-deadline.C:
+				timedOut = true
+			}
+		}
+
+		status, message := MountHealthy, ""
+		var responseTime time.Duration
+		select {
+		case <-probe.done:
+			responseTime = probe.end.Sub(probe.start)
+			switch {
+			case errors.Is(probe.err, syscall.ESTALE):
+				status, message = MountStale, probe.err.Error()
+			case probe.err != nil:
+				status, message = MountError, probe.err.Error()
+			}
+		default:
+			hungMountProbes[mount.Mountpoint] = probe
+			responseTime = time.Since(probe.start)
+			status, message = MountUnreachable, fmt.Sprintf("no response for %s", responseTime.Round(time.Second))
+		}
+
+		prefix := "netmount_" + utils.SanitizeMetricKey(strings.Trim(mount.Mountpoint, "/")) + "_"
+		s.Custom[prefix+"mountpoint"] = mount.Mountpoint
+		s.Custom[prefix+"status"] = status
+		s.Custom[prefix+"response_time"] = fmt.Sprintf("%.2f ms", float64(responseTime.Microseconds())/1000)
+		if status == MountHealthy {
+			s.Custom[prefix+"used_percent"] = fmt.Sprintf("%.2f%%", probe.usage.UsedPercent)
+		} else {
+			s.Custom[prefix+"error"] = message
+		}
+
+		// Report a mount failing (including failing from the start) and recovering
+		healthy := status == MountHealthy
+		wasHealthy, seen := prevMountHealthy[mount.Mountpoint]
+		if healthy != wasHealthy || !seen {
+			details := map[string]string{"mountpoint": mount.Mountpoint, "source": mount.Source, "fstype": mount.Fstype, "status": status}
+			if !healthy {
+				details["error"] = message
+				state := "is " + status
+				if status == MountError {
+					state = "failed"
+				}
+				s.Events = append(s.Events, models.NewEvent("mount_down",
+					fmt.Sprintf("%s (%s) %s: %s", mount.Mountpoint, mount.Source, state, message), details))
+			} else if seen {
+				s.Events = append(s.Events, models.NewEvent("mount_up",
+					fmt.Sprintf("%s (%s) is responding again", mount.Mountpoint, mount.Source), details))
+			}
+		}
+		prevMountHealthy[mount.Mountpoint] = healthy
+	}
+
+	// Forget unmounted filesystems, a probe still hanging on one is left to return on its own
+	for mountpoint := range prevMountHealthy {
+		if !mounted[mountpoint] {
+			delete(prevMountHealthy, mountpoint)
+			delete(hungMountProbes, mountpoint)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"errors"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReadNetworkMounts(t *testing.T) {
+	procRoot = "testdata/proc"
+	defer func() { procRoot = "/proc" }()
+
+	assert.Equal(t, []networkMount{
+		{Source: "nas:/export/media", Mountpoint: "/mnt/media", Fstype: "nfs4"},
+		{Source: "//nas/backup share", Mountpoint: "/mnt/backup share", Fstype: "cifs"},
+		{Source: "pi@lab:/home/pi", Mountpoint: "/home/me/lab", Fstype: "fuse.sshfs"},
+	}, readNetworkMounts())
+}
+
+func TestCollectNetworkMountData(t *testing.T) {
+	procRoot = "testdata/proc"
+	mountProbeTimeout = 100 * time.Millisecond
+	release := make(chan struct{})
+	calls := make(chan string, 10)
+	mountUsage = func(path string) (*disk.UsageStat, error) {
+		calls <- path
+		switch path {
+		case "/mnt/media":
+			// A dead NFS server blocks until it comes back
+			<-release
+		case "/mnt/backup share":
+			return nil, errors.New("host is down")
+		case "/home/me/lab":
+			// The export was recreated on the server
+			return nil, &fs.PathError{Op: "statfs", Path: path, Err: syscall.ESTALE}
+		}
+		return &disk.UsageStat{Path: path, UsedPercent: 42.5}, nil
+	}
+	defer func() {
+		procRoot = "/proc"
+		mountProbeTimeout = 2 * time.Second
+		mountUsage = disk.Usage
+		hungMountProbes = map[string]*mountProbe{}
+		prevMountHealthy = map[string]bool{}
+	}()
+
+	s := models.NewSystem()
+	start := time.Now()
+	collectNetworkMountData(s)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Len(t, calls, 3)
+
+	assert.Equal(t, MountUnreachable, s.Custom["netmount_mnt_media_status"])
+	assert.Equal(t, "no response for 0s", s.Custom["netmount_mnt_media_error"])
+	assert.Equal(t, MountError, s.Custom["netmount_mnt_backup_share_status"])
+	assert.Equal(t, "host is down", s.Custom["netmount_mnt_backup_share_error"])
+	assert.Equal(t, "/mnt/backup share", s.Custom["netmount_mnt_backup_share_mountpoint"])
+	assert.Equal(t, MountStale, s.Custom["netmount_home_me_lab_status"])
+	assert.Equal(t, "statfs /home/me/lab: stale file handle", s.Custom["netmount_home_me_lab_error"])
+	assert.Contains(t, s.Custom, "netmount_home_me_lab_response_time")
+
+	// Failing mounts are reported from the start
+	require.Len(t, s.Events, 3)
+	assert.Equal(t, "mount_down", s.Events[0].Type)
+	assert.Equal(t, "/mnt/media (nas:/export/media) is unreachable: no response for 0s", s.Events[0].Message)
+	assert.Equal(t, "/mnt/backup share (//nas/backup share) failed: host is down", s.Events[1].Message)
+	assert.Equal(t, "/home/me/lab (pi@lab:/home/pi) is stale: statfs /home/me/lab: stale file handle", s.Events[2].Message)
+
+	// No other call is made on the hung mount, and the sample doesn't wait for it
+	s = models.NewSystem()
+	start = time.Now()
+	collectNetworkMountData(s)
+	assert.Less(t, time.Since(start), mountProbeTimeout/2)
+	assert.Len(t, calls, 5)
+	assert.Equal(t, MountUnreachable, s.Custom["netmount_mnt_media_status"])
+	assert.Empty(t, s.Events)
+
+	// Once the hung call returns the mount is probed again
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+	s = models.NewSystem()
+	collectNetworkMountData(s)
+	assert.Len(t, calls, 8)
+	assert.Equal(t, MountHealthy, s.Custom["netmount_mnt_media_status"])
+	assert.Equal(t, "42.50%", s.Custom["netmount_mnt_media_used_percent"])
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "mount_up", s.Events[0].Type)
+	assert.Equal(t, "/mnt/media (nas:/export/media) is responding again", s.Events[0].Message)
+}
@@ -0,0 +1,133 @@
+package os
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// kmsgPath is the kernel log device. Reading it usually requires CAP_SYSLOG or
+// kernel.dmesg_restrict=0, without it OOM kills are still counted but unnamed.
+var kmsgPath = "/dev/kmsg"
+
+// oomKillPattern matches "Out of memory: Killed process 1234 (steam)" and the
+// older "Kill process 1234 (steam)" wording, including memory cgroup variants
+var oomKillPattern = regexp.MustCompile(`Kill(?:ed)? process (\d+) \(([^)]*)\)`)
+
+// oomKill is a kill reported in the kernel log
+type oomKill struct {
+	PID     string
+	Process string
+}
+
+var (
+	prevOOMKills   *uint64
+	kmsgOnce       sync.Once
+	kmsgMu         sync.Mutex
+	pendingOOMKill []oomKill
+)
+
+// parseKmsgOOMKill extracts the killed process from a /dev/kmsg record of the
+// form "priority,sequence,timestamp,flags;message"
+func parseKmsgOOMKill(record string) (oomKill, bool) {
+	_, message, found := strings.Cut(record, ";")
+	if !found {
+		return oomKill{}, false
+	}
+	match := oomKillPattern.FindStringSubmatch(message)
+	if match == nil {
+		return oomKill{}, false
+	}
+	return oomKill{PID: match[1], Process: match[2]}, true
+}
+
+// watchKmsg queues every OOM kill found in r until r is closed or fails. /dev/kmsg fails
+// reads with EPIPE when the ring buffer overwrote records not read yet, the next read
+// continues with the oldest record left.
+func watchKmsg(r io.Reader) {
+	reader := bufio.NewReader(r)
+	for {
+		record, err := reader.ReadString('\n')
+		if kill, ok := parseKmsgOOMKill(record); ok {
+			kmsgMu.Lock()
+			pendingOOMKill = append(pendingOOMKill, kill)
+			kmsgMu.Unlock()
+		}
+		if err != nil && !errors.Is(err, syscall.EPIPE) {
+			return
+		}
+	}
+}
+
+// startKmsgWatcher follows the kernel log in the background, skipping records
+// written before the agent started
+func startKmsgWatcher() {
+	file, err := os.Open(kmsgPath)
+	if err != nil {
+		log.Println("Kernel log unavailable, OOM kills will not be named:", err)
+		return
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		log.Println("Failed to seek kernel log:", err)
+		return
+	}
+	go func() {
+		defer file.Close()
+		watchKmsg(file)
+	}()
+}
+
+// collectOOMEvents emits an event for every process the OOM killer terminated since the previous sample
+func collectOOMEvents(s *models.System) {
+	kmsgOnce.Do(startKmsgWatcher)
+
+	vmstat, err := parseVmstat(filepath.Join(procRoot, "vmstat"))
+	if err != nil {
+		return
+	}
+	// oom_kill is only present on kernels 4.13 and newer
+	kills, ok := vmstat["oom_kill"]
+	if !ok {
+		return
+	}
+
+	// Kills named before the baseline stay queued for the next sample
+	if prevOOMKills == nil {
+		prevOOMKills = &kills
+		return
+	}
+
+	kmsgMu.Lock()
+	named := pendingOOMKill
+	pendingOOMKill = nil
+	kmsgMu.Unlock()
+
+	newKills := uint64(0)
+	if kills > *prevOOMKills {
+		newKills = kills - *prevOOMKills
+	}
+	prevOOMKills = &kills
+
+	for _, kill := range named {
+		s.Events = append(s.Events, models.NewEvent("oom_kill",
+			fmt.Sprintf("OOM killer terminated %s (pid %s)", kill.Process, kill.PID),
+			map[string]string{"process": kill.Process, "pid": kill.PID}))
+	}
+
+	// Kills the kernel log didn't tell us about, e.g. when /dev/kmsg isn't readable
+	if unnamed := int(newKills) - len(named); unnamed > 0 {
+		s.Events = append(s.Events, models.NewEvent("oom_kill",
+			fmt.Sprintf("OOM killer terminated %d process(es)", unnamed),
+			map[string]string{"count": fmt.Sprint(unnamed)}))
+	}
+}
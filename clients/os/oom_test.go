@@ -0,0 +1,96 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestParseKmsgOOMKill(t *testing.T) {
+	kill, ok := parseKmsgOOMKill("3,1234,5678901234,-;Out of memory: Killed process 4321 (steam) total-vm:1234kB, anon-rss:5678kB")
+	require.True(t, ok)
+	assert.Equal(t, oomKill{PID: "4321", Process: "steam"}, kill)
+
+	kill, ok = parseKmsgOOMKill("3,99,1,-;Memory cgroup out of memory: Kill process 77 (wine64-preload) score 900")
+	require.True(t, ok)
+	assert.Equal(t, "wine64-preload", kill.Process)
+
+	_, ok = parseKmsgOOMKill("6,1235,5678901299,-;usb 1-1: new high-speed USB device")
+	assert.False(t, ok)
+}
+
+func TestCollectOOMEvents(t *testing.T) {
+	procRoot = "testdata/proc"
+	kmsgOnce.Do(func() {}) // don't touch the real kernel log
+	defer func() {
+		procRoot = "/proc"
+		prevOOMKills = nil
+	}()
+
+	// First sample only records the baseline, a kill named before it is kept for the next one
+	watchKmsg(strings.NewReader("3,1,1,-;Out of memory: Killed process 4321 (steam) total-vm:1kB\n"))
+	s := models.NewSystem()
+	collectOOMEvents(s)
+	assert.Empty(t, s.Events)
+
+	// Two kills since the baseline, one of them named by the kernel log
+	procRoot = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(procRoot, "vmstat"), []byte("oom_kill 2\n"), 0644))
+
+	s = models.NewSystem()
+	collectOOMEvents(s)
+	require.Len(t, s.Events, 2)
+	assert.Equal(t, "oom_kill", s.Events[0].Type)
+	assert.Equal(t, "steam", s.Events[0].Details["process"])
+	assert.Equal(t, "1", s.Events[1].Details["count"])
+
+	// Counter unchanged, nothing new to report
+	s = models.NewSystem()
+	collectOOMEvents(s)
+	assert.Empty(t, s.Events)
+}
+
+// kmsgReader returns its records one per read and fails with its error between them
+type kmsgReader struct {
+	records []string
+	err     error
+}
+
+func (r *kmsgReader) Read(p []byte) (int, error) {
+	if len(r.records) == 0 {
+		return 0, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	if record == "" {
+		return 0, r.err
+	}
+	return copy(p, record), nil
+}
+
+func TestWatchKmsgOverrun(t *testing.T) {
+	defer func() { pendingOOMKill = nil }()
+
+	// The ring buffer overwrote records between the two kills
+	watchKmsg(&kmsgReader{err: syscall.EPIPE, records: []string{
+		"3,1,1,-;Out of memory: Killed process 4321 (steam) total-vm:1kB\n",
+		"",
+		"3,900,2,-;Out of memory: Killed process 5555 (chrome) total-vm:1kB\n",
+	}})
+	require.Len(t, pendingOOMKill, 2)
+	assert.Equal(t, "chrome", pendingOOMKill[1].Process)
+
+	// Other errors stop the watcher
+	pendingOOMKill = nil
+	watchKmsg(&kmsgReader{err: syscall.EIO, records: []string{
+		"",
+		"3,901,3,-;Out of memory: Killed process 6666 (java) total-vm:1kB\n",
+	}})
+	assert.Empty(t, pendingOOMKill)
+}
@@ -0,0 +1,189 @@
+package os
+
+import (
+	"bytes"
+	"context"
+	"device-chronicle-client/models"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPluginInterval = 60 * time.Second
+	defaultPluginTimeout  = 10 * time.Second
+	maxPluginStderr       = 4096 // Bytes of stderr kept for the error message
+)
+
+// pluginEnv are the variables passed on from the client's environment to every plugin
+var pluginEnv = []string{"PATH", "HOME", "LANG"}
+
+// plugin is a configured command and the result of its last run
+type plugin struct {
+	config   models.PluginConfig
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	running  bool
+	lastRun  time.Time
+	metrics  map[string]interface{}
+	duration time.Duration
+	lastErr  string
+}
+
+var (
+	pluginsMu sync.Mutex
+	plugins   []*plugin
+)
+
+// limitedBuffer keeps output up to its limit and discards the rest. onExceeded is called
+// once the limit is hit, so a command printing endlessly can be stopped.
+type limitedBuffer struct {
+	buf        bytes.Buffer // Not embedded, its ReadFrom would bypass the limit
+	limit      int
+	exceeded   bool
+	onExceeded func()
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		if !b.exceeded && b.onExceeded != nil {
+			b.onExceeded()
+		}
+		b.exceeded = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// configurePlugins validates and applies the plugins from the config
+func configurePlugins(configs []models.PluginConfig) error {
+	names := make(map[string]bool, len(configs))
+	applied := make([]*plugin, 0, len(configs))
+	for _, config := range configs {
+		if !metricNamePattern.MatchString(config.Name) {
+			return fmt.Errorf("plugin name %q must be lowercase letters, digits and underscores", config.Name)
+		}
+		if names[config.Name] {
+			return fmt.Errorf("plugin %q is defined more than once", config.Name)
+		}
+		names[config.Name] = true
+
+		if len(config.Command) == 0 {
+			return fmt.Errorf("plugin %q has no command", config.Name)
+		}
+		for _, variable := range config.Env {
+			if name, _, found := strings.Cut(variable, "="); !found || name == "" {
+				return fmt.Errorf("plugin %q: env %q must be KEY=value", config.Name, variable)
+			}
+		}
+
+		p := &plugin{config: config, interval: defaultPluginInterval, timeout: defaultPluginTimeout}
+		if config.Interval > 0 {
+			p.interval = time.Duration(config.Interval) * time.Second
+		}
+		if config.Timeout > 0 {
+			p.timeout = time.Duration(config.Timeout) * time.Second
+		}
+		applied = append(applied, p)
+	}
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	plugins = applied
+	return nil
+}
+
+// runPlugin runs a plugin command once and parses its output
+func runPlugin(config models.PluginConfig, timeout time.Duration) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, config.Command[0], config.Command[1:]...)
+	cmd.Env = make([]string, 0, len(pluginEnv)+len(config.Env))
+	for _, name := range pluginEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+	cmd.Env = append(cmd.Env, config.Env...)
+
+	// Don't wait forever for children of the command that keep its output open
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = time.Second
+
+	stdout := &limitedBuffer{limit: maxMetricsSize, onExceeded: cancel}
+	stderr := &limitedBuffer{limit: maxPluginStderr}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	switch {
+	case stdout.exceeded:
+		return nil, fmt.Errorf("output larger than %d bytes", maxMetricsSize)
+	case ctx.Err() == context.DeadlineExceeded:
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	case err != nil:
+		if message := strings.TrimSpace(stderr.buf.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+
+	output := bytes.TrimSpace(stdout.buf.Bytes())
+	if bytes.HasPrefix(output, []byte("{")) {
+		return parseJSONMetrics(output)
+	}
+	return parsePromText(output)
+}
+
+// run runs the plugin and keeps its result
+func (p *plugin) run() {
+	start := time.Now()
+	metrics, err := runPlugin(p.config, p.timeout)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running = false
+	p.duration = time.Since(start)
+	p.metrics = metrics
+	p.lastErr = ""
+	if err != nil {
+		p.lastErr = err.Error()
+	}
+}
+
+// collectPluginData starts every plugin that is due and reports the result of its
+// last run. Plugins run in the background so a hung command never delays the sample.
+func collectPluginData(s *models.System) {
+	pluginsMu.Lock()
+	current := plugins
+	pluginsMu.Unlock()
+
+	now := time.Now()
+	for _, p := range current {
+		p.mu.Lock()
+		if !p.running && now.Sub(p.lastRun) >= p.interval {
+			p.running = true
+			p.lastRun = now
+			go p.run()
+		}
+
+		// Nothing to report until the first run finished
+		if p.duration > 0 {
+			prefix := "plugin_" + p.config.Name + "_"
+			for key, value := range p.metrics {
+				s.Custom[prefix+key] = value
+			}
+			s.Custom[prefix+"duration"] = fmt.Sprintf("%.2f ms", float64(p.duration.Microseconds())/1000)
+			if p.lastErr != "" {
+				s.Custom[prefix+"last_error"] = p.lastErr
+			}
+		}
+		p.mu.Unlock()
+	}
+}
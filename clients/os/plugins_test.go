@@ -0,0 +1,119 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConfigurePlugins(t *testing.T) {
+	defer configurePlugins(nil)
+
+	require.NoError(t, configurePlugins([]models.PluginConfig{
+		{Name: "gpu", Command: []string{"/usr/local/bin/gpu-stats"}, Interval: 5, Env: []string{"GPU=0"}},
+		{Name: "sensors", Command: []string{"sensors", "-j"}},
+	}))
+	require.Len(t, plugins, 2)
+	assert.Equal(t, 5*time.Second, plugins[0].interval)
+	assert.Equal(t, defaultPluginInterval, plugins[1].interval)
+	assert.Equal(t, defaultPluginTimeout, plugins[1].timeout)
+
+	for _, config := range []models.PluginConfig{
+		{Name: "GPU", Command: []string{"gpu-stats"}},
+		{Name: "gpu"},
+		{Name: "gpu", Command: []string{"gpu-stats"}, Env: []string{"GPU"}},
+	} {
+		assert.Error(t, configurePlugins([]models.PluginConfig{config}), config)
+	}
+	assert.Error(t, configurePlugins([]models.PluginConfig{
+		{Name: "gpu", Command: []string{"gpu-stats"}},
+		{Name: "gpu", Command: []string{"gpu-stats", "--all"}},
+	}))
+}
+
+func TestRunPlugin(t *testing.T) {
+	metrics, err := runPlugin(models.PluginConfig{Command: []string{"sh", "-c", "echo 'game_fps{game=\"cs2\"} 143'"}}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"game_fps_cs2": 143.0}, metrics)
+
+	metrics, err = runPlugin(models.PluginConfig{Command: []string{"sh", "-c", `echo '{"gpu": {"load": 97}}'`}}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"gpu_load": 97.0}, metrics)
+
+	_, err = runPlugin(models.PluginConfig{Command: []string{"sh", "-c", "echo 'not metrics'"}}, time.Second)
+	assert.Error(t, err)
+
+	_, err = runPlugin(models.PluginConfig{Command: []string{"sh", "-c", "echo 'no gpu found' >&2; exit 3"}}, time.Second)
+	assert.EqualError(t, err, "exit status 3: no gpu found")
+
+	_, err = runPlugin(models.PluginConfig{Command: []string{"/nonexistent/plugin"}}, time.Second)
+	assert.Error(t, err)
+}
+
+func TestRunPluginLimits(t *testing.T) {
+	start := time.Now()
+	_, err := runPlugin(models.PluginConfig{Command: []string{"sleep", "10"}}, 100*time.Millisecond)
+	assert.EqualError(t, err, "timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Children of a script are killed with it, they don't keep its output open until WaitDelay
+	start = time.Now()
+	_, err = runPlugin(models.PluginConfig{Command: []string{"sh", "-c", "sleep 10 & sleep 10; wait"}}, 100*time.Millisecond)
+	assert.EqualError(t, err, "timed out after 100ms")
+	assert.Less(t, time.Since(start), 900*time.Millisecond)
+
+	_, err = runPlugin(models.PluginConfig{Command: []string{"sh", "-c", "while true; do echo 'fps 1'; done"}}, 5*time.Second)
+	assert.EqualError(t, err, "output larger than 1048576 bytes")
+}
+
+func TestRunPluginEnv(t *testing.T) {
+	os.Setenv("CHRONICLE_SECRET", "hunter2")
+	defer os.Unsetenv("CHRONICLE_SECRET")
+
+	metrics, err := runPlugin(models.PluginConfig{
+		Command: []string{"sh", "-c", `echo "{\"secret\": \"$CHRONICLE_SECRET\", \"gpu\": \"$GPU\", \"has_path\": $([ -n "$PATH" ] && echo true || echo false)}"`},
+		Env:     []string{"GPU=1"},
+	}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"secret": "", "gpu": "1", "has_path": true}, metrics)
+}
+
+func TestCollectPluginData(t *testing.T) {
+	require.NoError(t, configurePlugins([]models.PluginConfig{
+		{Name: "fps", Command: []string{"sh", "-c", "echo 'game_fps 143'"}},
+		{Name: "broken", Command: []string{"sh", "-c", "exit 1"}},
+	}))
+	defer configurePlugins(nil)
+
+	// The first collect only starts the plugins
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectPluginData(s)
+	assert.Empty(t, s.Custom)
+
+	require.Eventually(t, func() bool {
+		for _, p := range plugins {
+			p.mu.Lock()
+			running := p.running
+			p.mu.Unlock()
+			if running {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	s = &models.System{Custom: make(map[string]interface{})}
+	collectPluginData(s)
+	assert.Equal(t, 143.0, s.Custom["plugin_fps_game_fps"])
+	assert.Contains(t, s.Custom["plugin_fps_duration"], " ms")
+	assert.NotContains(t, s.Custom, "plugin_fps_last_error")
+	assert.Equal(t, "exit status 1", s.Custom["plugin_broken_last_error"])
+
+	// Not due again for a minute
+	for _, p := range plugins {
+		assert.False(t, p.running)
+	}
+}
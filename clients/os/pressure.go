@@ -0,0 +1,79 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pressureResources are the PSI files exposed under /proc/pressure
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// pressureStat is one "some" or "full" line of a PSI file
+type pressureStat struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  uint64 // cumulative stall time in microseconds
+}
+
+var (
+	prevPressureTotals map[string]uint64
+	prevPressureTime   time.Time
+)
+
+// parsePressure parses the contents of a /proc/pressure/* file into its "some" and "full" lines
+func parsePressure(data string) (map[string]pressureStat, error) {
+	stats := make(map[string]pressureStat)
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var kind string
+		var stat pressureStat
+		_, err := fmt.Sscanf(line, "%s avg10=%f avg60=%f avg300=%f total=%d",
+			&kind, &stat.Avg10, &stat.Avg60, &stat.Avg300, &stat.Total)
+		if err != nil {
+			return nil, fmt.Errorf("malformed pressure line %q: %w", line, err)
+		}
+		stats[kind] = stat
+	}
+	return stats, nil
+}
+
+// collectPressureData gathers Pressure Stall Information. Kernels built without
+// CONFIG_PSI or booted with psi=0 have no readable pressure files, in which case
+// nothing is reported.
+func collectPressureData(s *models.System) {
+	now := time.Now()
+	totals := make(map[string]uint64)
+
+	for _, resource := range pressureResources {
+		data, err := os.ReadFile(filepath.Join(procRoot, "pressure", resource))
+		if err != nil {
+			continue
+		}
+		stats, err := parsePressure(string(data))
+		if err != nil {
+			continue
+		}
+
+		for kind, stat := range stats {
+			key := fmt.Sprintf("psi_%s_%s", resource, kind)
+			s.Custom[key+"_avg10"] = fmt.Sprintf("%.2f%%", stat.Avg10)
+			s.Custom[key+"_avg60"] = fmt.Sprintf("%.2f%%", stat.Avg60)
+			s.Custom[key+"_avg300"] = fmt.Sprintf("%.2f%%", stat.Avg300)
+
+			// Stall time accumulated since the previous sample, in milliseconds per second
+			if prev, ok := prevPressureTotals[key]; ok && stat.Total >= prev {
+				if elapsed := now.Sub(prevPressureTime).Seconds(); elapsed > 0 {
+					s.Custom[key+"_total_rate"] = fmt.Sprintf("%.2f ms/s", float64(stat.Total-prev)/1000/elapsed)
+				}
+			}
+			totals[key] = stat.Total
+		}
+	}
+
+	prevPressureTotals = totals
+	prevPressureTime = now
+}
@@ -0,0 +1,53 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParsePressure(t *testing.T) {
+	stats, err := parsePressure("some avg10=1.50 avg60=0.75 avg300=0.25 total=123456789\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n")
+	require.NoError(t, err)
+	assert.Equal(t, pressureStat{Avg10: 1.5, Avg60: 0.75, Avg300: 0.25, Total: 123456789}, stats["some"])
+	assert.Equal(t, pressureStat{}, stats["full"])
+
+	_, err = parsePressure("some avg10=garbage")
+	assert.Error(t, err)
+}
+
+func TestCollectPressureData(t *testing.T) {
+	procRoot = "testdata/proc"
+	defer func() {
+		procRoot = "/proc"
+		prevPressureTotals = nil
+	}()
+
+	// Pretend the previous sample was one second ago with 1000ms less memory stall time
+	prevPressureTotals = map[string]uint64{"psi_memory_some": 9876543 - 1000000}
+	prevPressureTime = time.Now().Add(-time.Second)
+
+	s := models.NewSystem()
+	collectPressureData(s)
+
+	assert.Equal(t, "1.50%", s.Custom["psi_cpu_some_avg10"])
+	assert.Equal(t, "4.00%", s.Custom["psi_memory_full_avg60"])
+	assert.Equal(t, "0.15%", s.Custom["psi_io_full_avg300"])
+	assert.Contains(t, s.Custom, "psi_memory_some_total_rate")
+	assert.NotContains(t, s.Custom, "psi_cpu_some_total_rate")
+	assert.Equal(t, uint64(4567), prevPressureTotals["psi_io_some"])
+}
+
+func TestCollectPressureDataWithoutPSI(t *testing.T) {
+	procRoot = t.TempDir()
+	defer func() {
+		procRoot = "/proc"
+		prevPressureTotals = nil
+	}()
+
+	s := models.NewSystem()
+	collectPressureData(s)
+	assert.Empty(t, s.Custom)
+}
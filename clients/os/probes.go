@@ -0,0 +1,201 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe types
+const (
+	ProbeTCP  = "tcp"
+	ProbeHTTP = "http"
+)
+
+const (
+	defaultProbeTimeout = 5 * time.Second
+	maxProbeBody        = 1 << 20 // Bytes of an HTTP response searched for expect_body
+)
+
+// metricNamePattern keeps configured names usable in metric keys
+var metricNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// probeResult is the outcome of a single probe run
+type probeResult struct {
+	Up      bool
+	Latency time.Duration
+	Status  int // HTTP status code
+	Err     string
+}
+
+var (
+	probeConfigs []models.ProbeConfig
+	probeMu      sync.Mutex
+	probeResults map[string]probeResult
+	probeRunning atomic.Bool
+	prevProbeUp  map[string]bool
+)
+
+// configureProbes validates and applies the probes from the config
+func configureProbes(configs []models.ProbeConfig) error {
+	names := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		if !metricNamePattern.MatchString(config.Name) {
+			return fmt.Errorf("probe name %q must be lowercase letters, digits and underscores", config.Name)
+		}
+		if names[config.Name] {
+			return fmt.Errorf("probe %q is defined more than once", config.Name)
+		}
+		names[config.Name] = true
+
+		switch config.Type {
+		case ProbeTCP:
+			if _, _, err := net.SplitHostPort(config.Target); err != nil {
+				return fmt.Errorf("probe %q: target must be host:port: %w", config.Name, err)
+			}
+		case ProbeHTTP:
+			target, err := url.Parse(config.Target)
+			if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+				return fmt.Errorf("probe %q: target must be an http or https URL", config.Name)
+			}
+		default:
+			return fmt.Errorf("probe %q has unknown type %q", config.Name, config.Type)
+		}
+	}
+
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	probeConfigs = configs
+	probeResults = nil
+	prevProbeUp = make(map[string]bool)
+	return nil
+}
+
+// runProbe runs a single probe
+func runProbe(config models.ProbeConfig) probeResult {
+	timeout := defaultProbeTimeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	start := time.Now()
+	if config.Type == ProbeTCP {
+		conn, err := net.DialTimeout("tcp", config.Target, timeout)
+		if err != nil {
+			return probeResult{Err: err.Error()}
+		}
+		conn.Close()
+		return probeResult{Up: true, Latency: time.Since(start)}
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(config.Target)
+	if err != nil {
+		return probeResult{Err: err.Error()}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeBody))
+	result := probeResult{Latency: time.Since(start), Status: resp.StatusCode}
+	if err != nil {
+		result.Err = err.Error()
+		return result
+	}
+
+	switch {
+	case config.ExpectStatus > 0 && resp.StatusCode != config.ExpectStatus:
+		result.Err = fmt.Sprintf("status %d, expected %d", resp.StatusCode, config.ExpectStatus)
+	case config.ExpectStatus == 0 && resp.StatusCode >= 400:
+		result.Err = fmt.Sprintf("status %d", resp.StatusCode)
+	case config.ExpectBody != "" && !strings.Contains(string(body), config.ExpectBody):
+		result.Err = fmt.Sprintf("body does not contain %q", config.ExpectBody)
+	default:
+		result.Up = true
+	}
+	return result
+}
+
+// runProbes runs all probes in parallel and returns their results by name
+func runProbes(configs []models.ProbeConfig) map[string]probeResult {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]probeResult, len(configs))
+	for _, config := range configs {
+		wg.Add(1)
+		go func(config models.ProbeConfig) {
+			defer wg.Done()
+			result := runProbe(config)
+			mu.Lock()
+			results[config.Name] = result
+			mu.Unlock()
+		}(config)
+	}
+	wg.Wait()
+	return results
+}
+
+// collectProbeData reports the latest probe results and starts the next round.
+// Probes run in the background so a slow target never delays the sample.
+func collectProbeData(s *models.System) {
+	probeMu.Lock()
+	configs := probeConfigs
+	probeMu.Unlock()
+	if len(configs) == 0 {
+		return
+	}
+
+	if probeRunning.CompareAndSwap(false, true) {
+		go func() {
+			defer probeRunning.Store(false)
+			results := runProbes(configs)
+			probeMu.Lock()
+			probeResults = results
+			probeMu.Unlock()
+		}()
+	}
+
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	for _, config := range configs {
+		result, ok := probeResults[config.Name]
+		if !ok {
+			continue
+		}
+
+		prefix := "probe_" + config.Name
+		if result.Up {
+			s.Custom[prefix+"_up"] = 1
+		} else {
+			s.Custom[prefix+"_up"] = 0
+			s.Custom[prefix+"_error"] = result.Err
+		}
+		if result.Latency > 0 {
+			s.Custom[prefix+"_latency"] = fmt.Sprintf("%.2f ms", float64(result.Latency.Microseconds())/1000)
+		}
+		if result.Status > 0 {
+			s.Custom[prefix+"_status"] = result.Status
+		}
+
+		// Report going down (including being down from the start) and coming back up
+		wasUp, seen := prevProbeUp[config.Name]
+		if result.Up != wasUp || !seen {
+			details := map[string]string{"probe": config.Name, "target": config.Target}
+			if !result.Up {
+				details["error"] = result.Err
+				s.Events = append(s.Events, models.NewEvent("probe_down",
+					fmt.Sprintf("%s (%s) is unreachable: %s", config.Name, config.Target, result.Err), details))
+			} else if seen {
+				s.Events = append(s.Events, models.NewEvent("probe_up",
+					fmt.Sprintf("%s (%s) is reachable again", config.Name, config.Target), details))
+			}
+		}
+		prevProbeUp[config.Name] = result.Up
+	}
+}
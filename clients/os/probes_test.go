@@ -0,0 +1,122 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConfigureProbes(t *testing.T) {
+	defer configureProbes(nil)
+
+	assert.NoError(t, configureProbes([]models.ProbeConfig{
+		{Name: "nas", Type: ProbeTCP, Target: "192.168.1.10:445"},
+		{Name: "router_ui", Type: ProbeHTTP, Target: "https://192.168.1.1/"},
+	}))
+
+	for _, config := range []models.ProbeConfig{
+		{Name: "NAS", Type: ProbeTCP, Target: "192.168.1.10:445"},
+		{Name: "nas", Type: ProbeTCP, Target: "192.168.1.10"},
+		{Name: "nas", Type: ProbeHTTP, Target: "ftp://192.168.1.10/"},
+		{Name: "nas", Type: "icmp", Target: "192.168.1.10"},
+	} {
+		assert.Error(t, configureProbes([]models.ProbeConfig{config}), config)
+	}
+
+	assert.Error(t, configureProbes([]models.ProbeConfig{
+		{Name: "nas", Type: ProbeTCP, Target: "192.168.1.10:445"},
+		{Name: "nas", Type: ProbeTCP, Target: "192.168.1.10:22"},
+	}))
+}
+
+func TestRunProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+
+	result := runProbe(models.ProbeConfig{Name: "local", Type: ProbeTCP, Target: addr})
+	assert.True(t, result.Up)
+	assert.Positive(t, result.Latency)
+
+	// Nothing listens on the port anymore
+	listener.Close()
+	result = runProbe(models.ProbeConfig{Name: "local", Type: ProbeTCP, Target: addr, Timeout: 1})
+	assert.False(t, result.Up)
+	assert.NotEmpty(t, result.Err)
+}
+
+func TestRunProbeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"status":"healthy"}`)
+	}))
+	defer server.Close()
+
+	result := runProbe(models.ProbeConfig{Name: "api", Type: ProbeHTTP, Target: server.URL, ExpectBody: "healthy"})
+	assert.True(t, result.Up)
+	assert.Equal(t, http.StatusOK, result.Status)
+
+	result = runProbe(models.ProbeConfig{Name: "api", Type: ProbeHTTP, Target: server.URL, ExpectBody: "degraded"})
+	assert.False(t, result.Up)
+	assert.Equal(t, `body does not contain "degraded"`, result.Err)
+
+	result = runProbe(models.ProbeConfig{Name: "api", Type: ProbeHTTP, Target: server.URL + "/missing"})
+	assert.False(t, result.Up)
+	assert.Equal(t, "status 404", result.Err)
+
+	result = runProbe(models.ProbeConfig{Name: "api", Type: ProbeHTTP, Target: server.URL + "/missing", ExpectStatus: http.StatusNotFound})
+	assert.True(t, result.Up)
+
+	result = runProbe(models.ProbeConfig{Name: "api", Type: ProbeHTTP, Target: server.URL, ExpectStatus: http.StatusNoContent})
+	assert.False(t, result.Up)
+	assert.Equal(t, "status 200, expected 204", result.Err)
+}
+
+func TestCollectProbeData(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+
+	require.NoError(t, configureProbes([]models.ProbeConfig{{Name: "nas", Type: ProbeTCP, Target: addr, Timeout: 1}}))
+	defer configureProbes(nil)
+
+	// Each collect reports the latest finished round and starts the next one in the background
+	collect := func() *models.System {
+		s := &models.System{Custom: make(map[string]interface{})}
+		collectProbeData(s)
+		require.Eventually(t, func() bool { return !probeRunning.Load() }, 5*time.Second, 10*time.Millisecond)
+		return s
+	}
+
+	s := collect()
+	assert.Empty(t, s.Custom, "no round has finished yet")
+
+	s = collect()
+	assert.Equal(t, 1, s.Custom["probe_nas_up"])
+	assert.Contains(t, s.Custom["probe_nas_latency"], " ms")
+	assert.Empty(t, s.Events)
+
+	// The round started before the listener closed still reports up
+	listener.Close()
+	s = collect()
+	assert.Equal(t, 1, s.Custom["probe_nas_up"])
+
+	s = collect()
+	assert.Equal(t, 0, s.Custom["probe_nas_up"])
+	assert.NotEmpty(t, s.Custom["probe_nas_error"])
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "probe_down", s.Events[0].Type)
+
+	// Still down, no new event
+	s = collect()
+	assert.Empty(t, s.Events)
+}
@@ -0,0 +1,276 @@
+package os
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// States of an md array, from worst to best
+const (
+	RAIDInactive   = "inactive"
+	RAIDDegraded   = "degraded"
+	RAIDRebuilding = "rebuilding"
+	RAIDChecking   = "checking"
+	RAIDClean      = "clean"
+)
+
+var (
+	// md0 : active raid1 sdb1[1] sda1[0](F)
+	mdArrayPattern = regexp.MustCompile(`^(md\S*) : (\w+)(?: \([^)]*\))?(?: (raid\d+|linear|multipath))?((?: \S+\[\d+\](?:\([A-Z]\))*)*)`)
+	// 976630464 blocks super 1.2 [2/1] [U_]
+	mdDevicesPattern = regexp.MustCompile(`\[(\d+)/(\d+)\] \[([U_]+)\]`)
+	// [=====>.....]  recovery = 27.1% (264955520/976630272) finish=121.3min speed=97776K/sec
+	mdSyncPattern = regexp.MustCompile(`(resync|recovery|reshape|check|repair)\s*=\s*([\d.]+)%.*?(?:finish=(\S+))?(?:\s+speed=(\S+))?$`)
+)
+
+// mdArray is an md software RAID array from /proc/mdstat
+type mdArray struct {
+	Name    string
+	Active  bool // Inactive arrays are assembled but not started, e.g. missing members
+	Level   string
+	Total   int // Member devices the array should have
+	Working int // Member devices in sync
+	Failed  int // Members marked faulty
+	Spare   int
+	Sync    string  // Running resync, recovery, reshape, check or repair, if any
+	Percent float64 // Progress of Sync
+	Finish  string  // Estimated time left of Sync, e.g. "121.3min"
+	Speed   string  // e.g. "97776K/sec"
+}
+
+// degraded reports whether members of the array are missing
+func (a mdArray) degraded() bool {
+	return a.Working < a.Total
+}
+
+// state summarizes the health of the array
+func (a mdArray) state() string {
+	switch {
+	case !a.Active:
+		return RAIDInactive
+	case a.Sync == "recovery" || a.Sync == "reshape" || (a.Sync == "resync" && a.degraded()):
+		return RAIDRebuilding
+	case a.degraded():
+		return RAIDDegraded
+	case a.Sync != "":
+		return RAIDChecking
+	}
+	return RAIDClean
+}
+
+// parseMdstat parses the arrays in /proc/mdstat. Every array starts with a line naming
+// it, followed by indented lines with its size and member status and sync progress.
+func parseMdstat(data []byte) []mdArray {
+	var arrays []mdArray
+	var current *mdArray
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := mdArrayPattern.FindStringSubmatch(line); match != nil {
+			arrays = append(arrays, mdArray{Name: match[1], Active: match[2] == "active", Level: match[3]})
+			current = &arrays[len(arrays)-1]
+			for _, member := range strings.Fields(match[4]) {
+				switch {
+				case strings.Contains(member, "(F)"):
+					current.Failed++
+				case strings.Contains(member, "(S)"):
+					current.Spare++
+				}
+			}
+			continue
+		}
+		if current == nil || !strings.HasPrefix(line, " ") {
+			current = nil
+			continue
+		}
+
+		if match := mdDevicesPattern.FindStringSubmatch(line); match != nil {
+			current.Total, _ = strconv.Atoi(match[1])
+			current.Working, _ = strconv.Atoi(match[2])
+		}
+		if match := mdSyncPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			current.Sync = match[1]
+			current.Percent, _ = strconv.ParseFloat(match[2], 64)
+			current.Finish = match[3]
+			current.Speed = match[4]
+		}
+	}
+	return arrays
+}
+
+// btrfsErrorCounters are the counters in a btrfs device's error_stats, in the order
+// btrfs device stats prints them
+var btrfsErrorCounters = []string{"write_errs", "read_errs", "flush_errs", "corruption_errs", "generation_errs"}
+
+// btrfsFilesystem holds the error counters of a mounted btrfs filesystem, summed over its devices
+type btrfsFilesystem struct {
+	Name   string // Label, or the start of the UUID for unlabeled filesystems
+	Errors map[string]uint64
+}
+
+// readBtrfsFilesystems reads the error counters of every mounted btrfs filesystem from
+// /sys/fs/btrfs/<uuid>/devinfo/<devid>/error_stats, available since Linux 5.14
+func readBtrfsFilesystems() []btrfsFilesystem {
+	paths, _ := filepath.Glob(filepath.Join(sysRoot, "fs/btrfs/*/devinfo"))
+
+	var filesystems []btrfsFilesystem
+	for _, devinfo := range paths {
+		fsPath := filepath.Dir(devinfo)
+		name, _ := readSysfsString(filepath.Join(fsPath, "label"))
+		if name == "" {
+			name = filepath.Base(fsPath)
+			name = name[:min(len(name), 8)]
+		}
+
+		filesystem := btrfsFilesystem{Name: utils.SanitizeMetricKey(name), Errors: map[string]uint64{}}
+		stats, _ := filepath.Glob(filepath.Join(devinfo, "*/error_stats"))
+		for _, path := range stats {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				counter, value, found := strings.Cut(strings.TrimSpace(line), " ")
+				if !found {
+					continue
+				}
+				if count, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+					filesystem.Errors[counter] += count
+				}
+			}
+		}
+		filesystems = append(filesystems, filesystem)
+	}
+	sort.Slice(filesystems, func(i, j int) bool { return filesystems[i].Name < filesystems[j].Name })
+	return filesystems
+}
+
+var (
+	// Arrays and filesystems of the previous sample, nil before the first one
+	prevMdArrays map[string]mdArray
+	prevBtrfs    map[string]btrfsFilesystem
+)
+
+// diffMdArrays returns events for arrays losing members and getting them back
+func diffMdArrays(previous, current map[string]mdArray) []models.Event {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var events []models.Event
+	for _, name := range names {
+		before, existed := previous[name]
+		after := current[name]
+		switch {
+		case after.degraded() && (!existed || !before.degraded()):
+			events = append(events, models.NewEvent("raid_degraded",
+				fmt.Sprintf("%s is degraded, %d of %d devices working", name, after.Working, after.Total),
+				map[string]string{"array": name, "level": after.Level, "working": strconv.Itoa(after.Working), "total": strconv.Itoa(after.Total)}))
+		case existed && before.degraded() && !after.degraded():
+			events = append(events, models.NewEvent("raid_recovered",
+				fmt.Sprintf("%s has all %d devices working again", name, after.Total),
+				map[string]string{"array": name, "level": after.Level}))
+		}
+	}
+	return events
+}
+
+// diffBtrfs returns events for btrfs error counters that grew
+func diffBtrfs(previous, current map[string]btrfsFilesystem) []models.Event {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var events []models.Event
+	for _, name := range names {
+		before, existed := previous[name]
+		if !existed {
+			continue
+		}
+		var grown []string
+		details := map[string]string{"filesystem": name}
+		for _, counter := range btrfsErrorCounters {
+			// Counters persist across mounts and only go down when reset with btrfs device stats -z
+			if current[name].Errors[counter] > before.Errors[counter] {
+				delta := current[name].Errors[counter] - before.Errors[counter]
+				grown = append(grown, fmt.Sprintf("%d %s", delta, counter))
+				details[counter] = strconv.FormatUint(delta, 10)
+			}
+		}
+		if len(grown) > 0 {
+			events = append(events, models.NewEvent("btrfs_errors",
+				fmt.Sprintf("btrfs %s logged new errors: %s", name, strings.Join(grown, ", ")), details))
+		}
+	}
+	return events
+}
+
+// collectRAIDData reports the state and sync progress of md arrays and the error counters of
+// btrfs filesystems, with events when an array degrades or recovers or btrfs logs errors
+func collectRAIDData(s *models.System) {
+	mdArrays := map[string]mdArray{}
+	if data, err := os.ReadFile(filepath.Join(procRoot, "mdstat")); err == nil {
+		for _, array := range parseMdstat(data) {
+			mdArrays[array.Name] = array
+
+			prefix := "md_" + array.Name + "_"
+			s.Custom[prefix+"state"] = array.state()
+			if array.Level != "" {
+				s.Custom[prefix+"level"] = array.Level
+			}
+			if array.Total > 0 {
+				s.Custom[prefix+"devices"] = fmt.Sprintf("%d/%d", array.Working, array.Total)
+			}
+			if array.Failed > 0 {
+				s.Custom[prefix+"failed_devices"] = array.Failed
+			}
+			if array.Spare > 0 {
+				s.Custom[prefix+"spare_devices"] = array.Spare
+			}
+			if array.Sync != "" {
+				s.Custom[prefix+"sync_action"] = array.Sync
+				s.Custom[prefix+"sync_progress"] = array.Percent
+				if array.Finish != "" {
+					s.Custom[prefix+"sync_finish"] = array.Finish
+				}
+				if array.Speed != "" {
+					s.Custom[prefix+"sync_speed"] = array.Speed
+				}
+			}
+		}
+	}
+
+	btrfs := map[string]btrfsFilesystem{}
+	for _, filesystem := range readBtrfsFilesystems() {
+		btrfs[filesystem.Name] = filesystem
+
+		prefix := "btrfs_" + filesystem.Name + "_"
+		state := "ok"
+		for _, counter := range btrfsErrorCounters {
+			s.Custom[prefix+counter] = filesystem.Errors[counter]
+			if filesystem.Errors[counter] > 0 {
+				state = "errors"
+			}
+		}
+		s.Custom[prefix+"state"] = state
+	}
+
+	if prevMdArrays != nil {
+		s.Events = append(s.Events, diffMdArrays(prevMdArrays, mdArrays)...)
+		s.Events = append(s.Events, diffBtrfs(prevBtrfs, btrfs)...)
+	}
+	prevMdArrays, prevBtrfs = mdArrays, btrfs
+}
@@ -0,0 +1,88 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestParseMdstat(t *testing.T) {
+	data, err := os.ReadFile("testdata/proc/mdstat")
+	require.NoError(t, err)
+
+	arrays := parseMdstat(data)
+	require.Len(t, arrays, 5)
+
+	assert.Equal(t, mdArray{Name: "md1", Active: true, Level: "raid1", Total: 2, Working: 2}, arrays[0])
+	assert.Equal(t, RAIDClean, arrays[0].state())
+
+	assert.Equal(t, mdArray{
+		Name: "md2", Active: true, Level: "raid5", Total: 3, Working: 2,
+		Sync: "recovery", Percent: 27.1, Finish: "121.3min", Speed: "97776K/sec",
+	}, arrays[1])
+	assert.Equal(t, RAIDRebuilding, arrays[1].state())
+
+	assert.Equal(t, 1, arrays[2].Failed)
+	assert.Equal(t, RAIDDegraded, arrays[2].state())
+
+	assert.Equal(t, "raid10", arrays[3].Level)
+	assert.Equal(t, 1, arrays[3].Spare)
+	assert.Equal(t, "check", arrays[3].Sync)
+	assert.Equal(t, 3.5, arrays[3].Percent)
+	assert.Equal(t, RAIDChecking, arrays[3].state())
+
+	assert.Equal(t, mdArray{Name: "md127", Spare: 1}, arrays[4])
+	assert.Equal(t, RAIDInactive, arrays[4].state())
+}
+
+func TestReadBtrfsFilesystems(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	assert.Equal(t, []btrfsFilesystem{
+		{Name: "a1b2c3d4", Errors: map[string]uint64{"write_errs": 0, "read_errs": 0, "flush_errs": 0, "corruption_errs": 0, "generation_errs": 0}},
+		{Name: "data", Errors: map[string]uint64{"write_errs": 2, "read_errs": 3, "flush_errs": 0, "corruption_errs": 1, "generation_errs": 0}},
+	}, readBtrfsFilesystems())
+}
+
+func TestCollectRAIDData(t *testing.T) {
+	procRoot = "testdata/proc"
+	sysRoot = "testdata/sys"
+	defer func() { procRoot, sysRoot = "/proc", "/sys" }()
+	defer func() { prevMdArrays, prevBtrfs = nil, nil }()
+
+	s := &models.System{Custom: map[string]interface{}{}}
+	collectRAIDData(s)
+	assert.Empty(t, s.Events)
+	assert.Equal(t, RAIDRebuilding, s.Custom["md_md2_state"])
+	assert.Equal(t, "2/3", s.Custom["md_md2_devices"])
+	assert.Equal(t, 27.1, s.Custom["md_md2_sync_progress"])
+	assert.Equal(t, "121.3min", s.Custom["md_md2_sync_finish"])
+	assert.Equal(t, 1, s.Custom["md_md3_failed_devices"])
+	assert.Equal(t, RAIDInactive, s.Custom["md_md127_state"])
+	assert.Equal(t, uint64(3), s.Custom["btrfs_data_read_errs"])
+	assert.Equal(t, "errors", s.Custom["btrfs_data_state"])
+	assert.Equal(t, "ok", s.Custom["btrfs_a1b2c3d4_state"])
+
+	// md1 loses a member, md3 gets its back and btrfs logs more errors
+	prevMdArrays["md1"] = mdArray{Name: "md1", Active: true, Level: "raid1", Total: 2, Working: 2}
+	prevMdArrays["md3"] = mdArray{Name: "md3", Active: true, Level: "raid1", Total: 2, Working: 2}
+	prevBtrfs["data"].Errors["read_errs"] = 1
+	events := append(diffMdArrays(prevMdArrays, map[string]mdArray{
+		"md1": {Name: "md1", Active: true, Level: "raid1", Total: 2, Working: 1},
+	}), diffBtrfs(prevBtrfs, map[string]btrfsFilesystem{
+		"data": {Name: "data", Errors: map[string]uint64{"read_errs": 3, "write_errs": 2}},
+	})...)
+	require.Len(t, events, 2)
+	assert.Equal(t, "raid_degraded", events[0].Type)
+	assert.Equal(t, "md1 is degraded, 1 of 2 devices working", events[0].Message)
+	assert.Equal(t, "btrfs_errors", events[1].Type)
+	assert.Equal(t, "btrfs data logged new errors: 2 read_errs", events[1].Message)
+
+	events = diffMdArrays(map[string]mdArray{"md3": {Total: 2, Working: 1}},
+		map[string]mdArray{"md3": {Name: "md3", Active: true, Level: "raid1", Total: 2, Working: 2}})
+	require.Len(t, events, 1)
+	assert.Equal(t, "raid_recovered", events[0].Type)
+}
@@ -0,0 +1,167 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// energyCounter is a cumulative energy counter of one power domain
+type energyCounter struct {
+	Domain   string // e.g. "package_0", "package_0_core" or "package_0_dram"
+	EnergyUJ uint64
+	MaxUJ    uint64 // Value at which the counter wraps to zero, 0 if it doesn't wrap
+	Total    bool   // Counts toward the energy consumed by the whole device
+}
+
+// amdEnergyLabelPattern matches amd_energy labels like Esocket0 and Ecore012
+var amdEnergyLabelPattern = regexp.MustCompile(`^E(socket|core)(\d+)$`)
+
+var (
+	prevEnergy     map[string]uint64
+	prevEnergyTime time.Time
+)
+
+// readRAPLCounters reads RAPL domains from /sys/class/powercap/intel-rapl:*, which
+// the intel_rapl driver also exposes for AMD Zen CPUs. energy_uj is root-only on most
+// distributions since 5.10, nothing is reported when it can't be read.
+func readRAPLCounters() []energyCounter {
+	zones, _ := filepath.Glob(filepath.Join(sysRoot, "class/powercap/intel-rapl:*"))
+
+	names := make(map[string]string)
+	for _, zone := range zones {
+		if name, err := readSysfsString(filepath.Join(zone, "name")); err == nil {
+			names[filepath.Base(zone)] = strings.ReplaceAll(name, "-", "_")
+		}
+	}
+
+	hasPsys := false
+	for _, name := range names {
+		if name == "psys" {
+			hasPsys = true
+		}
+	}
+
+	counters := []energyCounter{}
+	for _, zone := range zones {
+		base := filepath.Base(zone)
+		name, ok := names[base]
+		if !ok {
+			continue
+		}
+		energy, err := readSysfsUint(filepath.Join(zone, "energy_uj"))
+		if err != nil {
+			continue
+		}
+		maxEnergy, _ := readSysfsUint(filepath.Join(zone, "max_energy_range_uj"))
+
+		// Subzones are named "intel-rapl:<package>:<n>", prefix them with their package
+		domain := name
+		if parts := strings.Split(base, ":"); len(parts) == 3 {
+			domain = names[parts[0]+":"+parts[1]] + "_" + name
+		}
+
+		// psys covers the whole SoC when present, otherwise add up packages and DRAM
+		// (DRAM is not part of the package domain)
+		total := name == "psys"
+		if !hasPsys {
+			total = strings.HasPrefix(name, "package") || name == "dram"
+		}
+
+		counters = append(counters, energyCounter{Domain: domain, EnergyUJ: energy, MaxUJ: maxEnergy, Total: total})
+	}
+	return counters
+}
+
+// readAMDEnergyCounters reads the amd_energy hwmon driver found on some EPYC and Ryzen systems
+func readAMDEnergyCounters() []energyCounter {
+	chips, _ := filepath.Glob(filepath.Join(sysRoot, "class/hwmon/hwmon*"))
+
+	counters := []energyCounter{}
+	for _, chip := range chips {
+		if name, err := readSysfsString(filepath.Join(chip, "name")); err != nil || name != "amd_energy" {
+			continue
+		}
+		inputs, _ := filepath.Glob(filepath.Join(chip, "energy*_input"))
+		for _, input := range inputs {
+			label, err := readSysfsString(strings.TrimSuffix(input, "_input") + "_label")
+			if err != nil {
+				continue
+			}
+			match := amdEnergyLabelPattern.FindStringSubmatch(label)
+			if match == nil {
+				continue
+			}
+			energy, err := readSysfsUint(input)
+			if err != nil {
+				continue
+			}
+			domain := fmt.Sprintf("package_%s", match[2])
+			if match[1] == "core" {
+				domain = fmt.Sprintf("core_%s", match[2])
+			}
+			counters = append(counters, energyCounter{Domain: domain, EnergyUJ: energy, Total: match[1] == "socket"})
+		}
+	}
+	return counters
+}
+
+// energyDelta returns the energy used between two readings of a counter, accounting for wraparound
+func energyDelta(prev, cur, maxEnergy uint64) (uint64, bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	if maxEnergy > prev {
+		return maxEnergy - prev + cur, true
+	}
+	// Counter was reset, e.g. by a driver reload
+	return 0, false
+}
+
+// collectPowerData reports the power draw of every RAPL domain and the energy the
+// device used since the previous sample, in joules, for the server to add up
+func collectPowerData(s *models.System) {
+	now := time.Now()
+	counters := readRAPLCounters()
+	if len(counters) == 0 {
+		counters = readAMDEnergyCounters()
+	}
+	if len(counters) == 0 {
+		return
+	}
+
+	elapsed := now.Sub(prevEnergyTime).Seconds()
+	current := make(map[string]uint64, len(counters))
+	totalJoules := 0.0
+	reported := false
+
+	for _, counter := range counters {
+		current[counter.Domain] = counter.EnergyUJ
+		prev, ok := prevEnergy[counter.Domain]
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		delta, ok := energyDelta(prev, counter.EnergyUJ, counter.MaxUJ)
+		if !ok {
+			continue
+		}
+
+		joules := float64(delta) / 1e6
+		s.Custom["power_"+counter.Domain] = fmt.Sprintf("%.2f W", joules/elapsed)
+		if counter.Total {
+			totalJoules += joules
+			reported = true
+		}
+	}
+
+	if reported {
+		s.Custom["power_total"] = fmt.Sprintf("%.2f W", totalJoules/elapsed)
+		s.Custom["energy_joules"] = totalJoules
+	}
+
+	prevEnergy = current
+	prevEnergyTime = now
+}
@@ -0,0 +1,91 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEnergyDelta(t *testing.T) {
+	delta, ok := energyDelta(100, 250, 1000)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(150), delta)
+
+	// Counter wrapped past max_energy_range_uj
+	delta, ok = energyDelta(900, 50, 1000)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(150), delta)
+
+	// Counter went backwards without a known range
+	_, ok = energyDelta(900, 50, 0)
+	assert.False(t, ok)
+}
+
+func TestReadRAPLCounters(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+
+	counters := readRAPLCounters()
+	require.Len(t, counters, 4)
+
+	domains := make(map[string]energyCounter)
+	for _, counter := range counters {
+		domains[counter.Domain] = counter
+	}
+	assert.Equal(t, energyCounter{Domain: "package_0", EnergyUJ: 262143000000, MaxUJ: 262143328850, Total: true}, domains["package_0"])
+	assert.False(t, domains["package_0_core"].Total)
+	assert.False(t, domains["package_0_uncore"].Total)
+	assert.True(t, domains["package_0_dram"].Total)
+}
+
+func TestCollectPowerData(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() {
+		sysRoot = "/sys"
+		prevEnergy = nil
+	}()
+
+	// Two seconds ago the package counter was 1000 J lower, the DRAM counter
+	// has wrapped around since and used 10 J
+	prevEnergy = map[string]uint64{
+		"package_0":      262143000000 - 100000000,
+		"package_0_core": 1000000000 - 60000000,
+		"package_0_dram": 65712999613 - 8000000,
+	}
+	prevEnergyTime = time.Now().Add(-2 * time.Second)
+
+	s := models.NewSystem()
+	collectPowerData(s)
+
+	assert.Equal(t, "50.00 W", roundedPower(s, "power_package_0"))
+	assert.Equal(t, "30.00 W", roundedPower(s, "power_package_0_core"))
+	assert.NotContains(t, s.Custom, "power_package_0_uncore")
+	require.Contains(t, s.Custom, "energy_joules")
+	// 100 J package + 8 J before the wrap + 2000 J after it
+	assert.InDelta(t, 2108.0, s.Custom["energy_joules"], 0.001)
+}
+
+func TestCollectPowerDataAMDEnergy(t *testing.T) {
+	sysRoot = "testdata/amd/sys"
+	defer func() {
+		sysRoot = "/sys"
+		prevEnergy = nil
+	}()
+
+	// First sample only records the counters
+	s := models.NewSystem()
+	collectPowerData(s)
+	assert.Empty(t, s.Custom)
+	assert.Equal(t, map[string]uint64{"core_000": 5000000000, "package_0": 90000000000}, prevEnergy)
+}
+
+// roundedPower re-formats a power reading to whole watts, the elapsed time in tests is never exact
+func roundedPower(s *models.System, key string) string {
+	var watts float64
+	fmt.Sscanf(s.Custom[key].(string), "%f W", &watts)
+	return fmt.Sprintf("%.2f W", math.Round(watts))
+}
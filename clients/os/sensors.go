@@ -0,0 +1,121 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"strings"
+)
+
+// Sensor roles that can be assigned in the "sensors" section of config.json
+const (
+	SensorRoleCPU        = "cpu"
+	SensorRoleChipset    = "chipset"
+	SensorRoleNVMe       = "nvme"
+	SensorRoleGPUAmbient = "gpu-ambient"
+	SensorRoleIgnore     = "ignore"
+)
+
+var validSensorRoles = map[string]bool{
+	SensorRoleCPU:        true,
+	SensorRoleChipset:    true,
+	SensorRoleNVMe:       true,
+	SensorRoleGPUAmbient: true,
+	SensorRoleIgnore:     true,
+}
+
+// gpuChips are hwmon drivers of graphics cards
+var gpuChips = []string{"amdgpu", "radeon", "nouveau", "i915", "xe"}
+
+// sensorConfigs is the user's sensor mapping keyed by lowercased ID, key or label
+var sensorConfigs map[string]models.SensorConfig
+
+// configureSensors validates and applies the sensor mapping from the config
+func configureSensors(configs map[string]models.SensorConfig) error {
+	mapping := make(map[string]models.SensorConfig, len(configs))
+	for match, config := range configs {
+		if !validSensorRoles[config.Role] {
+			return fmt.Errorf("sensor %q has unknown role %q", match, config.Role)
+		}
+		mapping[strings.ToLower(match)] = config
+	}
+	sensorConfigs = mapping
+	return nil
+}
+
+// configuredSensor looks a sensor up in the user's mapping by ID, then key, then label
+func configuredSensor(sensor models.Sensor) (models.SensorConfig, bool) {
+	for _, match := range []string{sensor.ID, sensorKey(sensor), sensor.Label} {
+		if config, ok := sensorConfigs[strings.ToLower(match)]; ok {
+			return config, true
+		}
+	}
+	return models.SensorConfig{}, false
+}
+
+// guessSensorRole guesses what a sensor measures from its chip and label
+func guessSensorRole(sensor models.Sensor) string {
+	if sensor.Kind != "temp" {
+		return ""
+	}
+	if sensor.Chip == "nvme" {
+		return SensorRoleNVMe
+	}
+	for _, chip := range gpuChips {
+		if sensor.Chip == chip {
+			return SensorRoleGPUAmbient
+		}
+	}
+	if cpuPatternRank(sensor) < len(cpuPatterns) {
+		return SensorRoleCPU
+	}
+
+	key := sensorKey(sensor)
+	for _, pattern := range chipsetPatterns {
+		if strings.Contains(key, pattern) {
+			return SensorRoleChipset
+		}
+	}
+	return ""
+}
+
+// cpuPatternRank returns the index of the first CPU pattern matching the sensor,
+// or len(cpuPatterns) if none does. Lower is a better CPU temperature candidate.
+func cpuPatternRank(sensor models.Sensor) int {
+	key := sensorKey(sensor)
+	for i, pattern := range cpuPatterns {
+		if strings.Contains(key, pattern) {
+			return i
+		}
+	}
+	return len(cpuPatterns)
+}
+
+// classifySensors sets the role and display name of every sensor
+func classifySensors(sensors []models.Sensor) []models.Sensor {
+	for i, sensor := range sensors {
+		if config, ok := configuredSensor(sensor); ok {
+			sensors[i].Role = config.Role
+			sensors[i].Name = config.Name
+			sensors[i].Configured = true
+		} else {
+			sensors[i].Role = guessSensorRole(sensor)
+		}
+	}
+	return sensors
+}
+
+// hasConfiguredSensors reports whether any of the sensors is in the user's mapping
+func hasConfiguredSensors(sensors []models.Sensor) bool {
+	for _, sensor := range sensors {
+		if sensor.Configured {
+			return true
+		}
+	}
+	return false
+}
+
+// LinuxSensors returns every hwmon sensor with its configured or guessed role,
+// including ignored ones
+func LinuxSensors() []models.Sensor {
+	return classifySensors(readHwmonSensors())
+}
@@ -0,0 +1,83 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConfigureSensors(t *testing.T) {
+	defer func() { sensorConfigs = nil }()
+
+	assert.NoError(t, configureSensors(map[string]models.SensorConfig{"Tctl": {Role: "cpu"}}))
+	assert.Error(t, configureSensors(map[string]models.SensorConfig{"Tctl": {Role: "gpu"}}))
+}
+
+func TestClassifySensors(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() {
+		sysRoot = "/sys"
+		sensorConfigs = nil
+	}()
+
+	roles := func() map[string]string {
+		result := make(map[string]string)
+		for _, sensor := range LinuxSensors() {
+			result[sensor.ID] = sensor.Role
+		}
+		return result
+	}
+
+	guessed := roles()
+	assert.Equal(t, SensorRoleCPU, guessed["k10temp/temp1"])
+	assert.Equal(t, SensorRoleCPU, guessed["nct6798/temp2"])
+	assert.Equal(t, SensorRoleChipset, guessed["nct6798/temp10"])
+	assert.Equal(t, SensorRoleNVMe, guessed["nvme_nvme0/temp1"])
+	assert.Equal(t, SensorRoleGPUAmbient, guessed["amdgpu/temp1"])
+	assert.Equal(t, "", guessed["nct6798/temp1"])
+	assert.Equal(t, "", guessed["nct6798/fan1"])
+
+	// Mapping by ID, key and label, matched case-insensitively
+	require.NoError(t, configureSensors(map[string]models.SensorConfig{
+		"nct6798/temp1":  {Role: "chipset", Name: "Motherboard"},
+		"NCT6798_CPUTIN": {Role: "ignore"},
+		"tccd1":          {Role: "cpu", Name: "CCD 1"},
+	}))
+	configured := roles()
+	assert.Equal(t, SensorRoleChipset, configured["nct6798/temp1"])
+	assert.Equal(t, SensorRoleIgnore, configured["nct6798/temp2"])
+	assert.Equal(t, SensorRoleCPU, configured["k10temp/temp3"])
+
+	for _, sensor := range LinuxSensors() {
+		if sensor.ID == "nct6798/temp1" {
+			assert.True(t, sensor.Configured)
+			assert.Equal(t, "Motherboard", sensor.Name)
+		}
+	}
+}
+
+func TestCollectTemperatureDataWithSensorConfig(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() {
+		sysRoot = "/sys"
+		sensorConfigs = nil
+	}()
+
+	require.NoError(t, configureSensors(map[string]models.SensorConfig{
+		"Tccd1":          {Role: "cpu"},
+		"SYSTIN":         {Role: "chipset"},
+		"nct6798/temp10": {Role: "chipset"},
+		"CPUTIN":         {Role: "ignore"},
+	}))
+
+	s := models.NewSystem()
+	collectTemperatureData(s)
+
+	// The configured Tccd1 wins over the hotter, guessed Tctl
+	assert.Equal(t, "55.12°C", s.CPUTemp)
+	assert.Equal(t, "44.50°C", s.AverageChipsetTemp)
+	for _, sensor := range s.Sensors {
+		assert.NotEqual(t, "nct6798/temp2", sensor.ID, "ignored sensors are not reported")
+	}
+}
@@ -0,0 +1,280 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSMARTInterval = time.Hour
+	defaultSMARTTimeout  = 30 * time.Second
+
+	// smartctl exit status bits that mean it couldn't read the drive, the others report
+	// drive problems found and still come with the drive's data
+	smartctlFatalStatus = 0x3
+)
+
+// ATA attributes read from smartctl's attribute table, by ID
+const (
+	ataReallocatedSectors = 5
+	ataWearLevelingCount  = 177 // Samsung, normalized to the share of life left
+	ataPendingSectors     = 197
+	ataUncorrectable      = 198
+	ataMediaWearout       = 233 // Intel, normalized to the share of life left
+)
+
+// smartctlOutput is the part of smartctl --json --all output that is reported
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	ModelName   string `json:"model_name"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature *struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime *struct {
+		Hours int `json:"hours"`
+	} `json:"power_on_time"`
+	ATAAttributes *struct {
+		Table []struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			Value int    `json:"value"`
+			Raw   struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeHealth *struct {
+		PercentageUsed int   `json:"percentage_used"`
+		AvailableSpare int   `json:"available_spare"`
+		MediaErrors    int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// smartStatus is the health of a drive, nil fields aren't reported by the drive
+type smartStatus struct {
+	Model              string
+	Passed             *bool
+	Temperature        *int
+	PowerOnHours       *int
+	ReallocatedSectors *int64
+	PendingSectors     *int64
+	Uncorrectable      *int64
+	MediaErrors        *int64
+	WearPercent        *int // Share of the rated endurance used
+	AvailableSpare     *int
+}
+
+// parseSmartctl parses the JSON output of smartctl --json --all for ATA and NVMe drives
+func parseSmartctl(data []byte) (smartStatus, error) {
+	var output smartctlOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return smartStatus{}, fmt.Errorf("invalid smartctl output: %w", err)
+	}
+	if output.Smartctl.ExitStatus&smartctlFatalStatus != 0 {
+		var messages []string
+		for _, message := range output.Smartctl.Messages {
+			messages = append(messages, message.String)
+		}
+		return smartStatus{}, fmt.Errorf("smartctl exit status %d: %s", output.Smartctl.ExitStatus, strings.Join(messages, "; "))
+	}
+
+	status := smartStatus{Model: output.ModelName}
+	if output.SmartStatus != nil {
+		status.Passed = &output.SmartStatus.Passed
+	}
+	if output.Temperature != nil {
+		status.Temperature = &output.Temperature.Current
+	}
+	if output.PowerOnTime != nil {
+		status.PowerOnHours = &output.PowerOnTime.Hours
+	}
+
+	if output.ATAAttributes != nil {
+		for _, attribute := range output.ATAAttributes.Table {
+			raw := attribute.Raw.Value
+			switch attribute.ID {
+			case ataReallocatedSectors:
+				status.ReallocatedSectors = &raw
+			case ataPendingSectors:
+				status.PendingSectors = &raw
+			case ataUncorrectable:
+				status.Uncorrectable = &raw
+			case ataWearLevelingCount, ataMediaWearout:
+				wear := 100 - attribute.Value
+				status.WearPercent = &wear
+			}
+		}
+	}
+	if health := output.NVMeHealth; health != nil {
+		status.WearPercent = &health.PercentageUsed
+		status.AvailableSpare = &health.AvailableSpare
+		status.MediaErrors = &health.MediaErrors
+	}
+	return status, nil
+}
+
+// smartChecker runs smartctl for every drive on its schedule and keeps the last results
+type smartChecker struct {
+	command  []string
+	devices  []string
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	running bool
+	lastRun time.Time
+	checked bool
+	results map[string]smartStatus // Device name -> status
+	errs    []string
+}
+
+var (
+	smartMu sync.Mutex
+	smart   *smartChecker
+)
+
+// configureSMART applies the SMART config. A nil config turns the check off.
+func configureSMART(config *models.SMARTConfig) error {
+	var checker *smartChecker
+	if config != nil {
+		if config.Interval < 0 || config.Timeout < 0 {
+			return fmt.Errorf("smart interval and timeout can't be negative")
+		}
+		checker = &smartChecker{
+			command:  config.Command,
+			devices:  config.Devices,
+			interval: defaultSMARTInterval,
+			timeout:  defaultSMARTTimeout,
+		}
+		if len(checker.command) == 0 {
+			checker.command = []string{"smartctl"}
+		}
+		if config.Interval > 0 {
+			checker.interval = time.Duration(config.Interval) * time.Second
+		}
+		if config.Timeout > 0 {
+			checker.timeout = time.Duration(config.Timeout) * time.Second
+		}
+	}
+
+	smartMu.Lock()
+	defer smartMu.Unlock()
+	smart = checker
+	return nil
+}
+
+// check runs smartctl for every drive once and keeps the results
+func (c *smartChecker) check() {
+	devices := c.devices
+	if len(devices) == 0 {
+		for _, disk := range readInventoryDisks() {
+			devices = append(devices, "/dev/"+disk.Name)
+		}
+	}
+
+	results := make(map[string]smartStatus, len(devices))
+	var errs []string
+	for _, device := range devices {
+		command := append(append([]string{}, c.command...), "--json", "--all", device)
+		// smartctl sets its exit status to report drive problems, the output tells whether it read the drive
+		output, runErr := runCommand(command, c.timeout)
+		status, err := parseSmartctl([]byte(output))
+		if err != nil {
+			// Without output the command itself failed, e.g. smartctl isn't installed
+			if runErr != nil && strings.TrimSpace(output) == "" {
+				err = runErr
+			}
+			errs = append(errs, device+": "+err.Error())
+			continue
+		}
+		results[filepath.Base(device)] = status
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running = false
+	c.checked = true
+	c.results = results
+	c.errs = errs
+}
+
+// collectSMARTData starts a drive health check when one is due and reports the results of
+// the last one. Checks run in the background, smartctl may wake up sleeping drives.
+func collectSMARTData(s *models.System) {
+	smartMu.Lock()
+	checker := smart
+	smartMu.Unlock()
+	if checker == nil {
+		return
+	}
+
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	now := time.Now()
+	if !checker.running && now.Sub(checker.lastRun) >= checker.interval {
+		checker.running = true
+		checker.lastRun = now
+		go checker.check()
+	}
+
+	// Nothing to report until the first check finished
+	if !checker.checked {
+		return
+	}
+	for device, status := range checker.results {
+		prefix := "smart_" + utils.SanitizeMetricKey(device) + "_"
+		if status.Model != "" {
+			s.Custom[prefix+"model"] = status.Model
+		}
+		if status.Passed != nil {
+			health := "PASSED"
+			if !*status.Passed {
+				health = "FAILED"
+			}
+			s.Custom[prefix+"health"] = health
+		}
+		if status.Temperature != nil {
+			s.Custom[prefix+"temperature"] = *status.Temperature
+		}
+		if status.PowerOnHours != nil {
+			s.Custom[prefix+"power_on_hours"] = *status.PowerOnHours
+		}
+		if status.ReallocatedSectors != nil {
+			s.Custom[prefix+"reallocated_sectors"] = *status.ReallocatedSectors
+		}
+		if status.PendingSectors != nil {
+			s.Custom[prefix+"pending_sectors"] = *status.PendingSectors
+		}
+		if status.Uncorrectable != nil {
+			s.Custom[prefix+"uncorrectable_sectors"] = *status.Uncorrectable
+		}
+		if status.MediaErrors != nil {
+			s.Custom[prefix+"media_errors"] = *status.MediaErrors
+		}
+		if status.WearPercent != nil {
+			s.Custom[prefix+"wear_percent"] = *status.WearPercent
+		}
+		if status.AvailableSpare != nil {
+			s.Custom[prefix+"available_spare"] = *status.AvailableSpare
+		}
+	}
+	if len(checker.errs) > 0 {
+		s.Custom["smart_error"] = strings.Join(checker.errs, "; ")
+	}
+}
@@ -0,0 +1,81 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+func readSmartctlFixture(t *testing.T, name string) (smartStatus, error) {
+	data, err := os.ReadFile("testdata/smartctl/" + name)
+	require.NoError(t, err)
+	return parseSmartctl(data)
+}
+
+func TestParseSmartctlATA(t *testing.T) {
+	status, err := readSmartctlFixture(t, "sda.json")
+	require.NoError(t, err)
+	assert.Equal(t, "Samsung SSD 860 EVO 500GB", status.Model)
+	assert.True(t, *status.Passed)
+	assert.Equal(t, 34, *status.Temperature)
+	assert.Equal(t, 41823, *status.PowerOnHours)
+	assert.Equal(t, int64(12), *status.ReallocatedSectors)
+	assert.Equal(t, int64(2), *status.PendingSectors)
+	assert.Equal(t, int64(0), *status.Uncorrectable)
+	assert.Equal(t, 13, *status.WearPercent)
+	assert.Nil(t, status.MediaErrors)
+}
+
+func TestParseSmartctlNVMe(t *testing.T) {
+	status, err := readSmartctlFixture(t, "nvme0n1.json")
+	require.NoError(t, err)
+	assert.True(t, *status.Passed)
+	assert.Equal(t, 3, *status.WearPercent)
+	assert.Equal(t, 100, *status.AvailableSpare)
+	assert.Equal(t, int64(0), *status.MediaErrors)
+	assert.Nil(t, status.ReallocatedSectors)
+}
+
+func TestParseSmartctlFailures(t *testing.T) {
+	// Exit status 24 reports a failing drive, its data is still read
+	status, err := readSmartctlFixture(t, "failing.json")
+	require.NoError(t, err)
+	assert.False(t, *status.Passed)
+	assert.Equal(t, int64(3896), *status.ReallocatedSectors)
+	assert.Nil(t, status.WearPercent)
+
+	_, err = readSmartctlFixture(t, "permission.json")
+	assert.EqualError(t, err, "smartctl exit status 2: Smartctl open device: /dev/sda failed: Permission denied")
+}
+
+func TestCollectSMARTData(t *testing.T) {
+	defer configureSMART(nil)
+
+	// Stands in for smartctl, printing the fixture of the device it's given
+	require.NoError(t, configureSMART(&models.SMARTConfig{
+		Command: []string{"sh", "-c", `cat "testdata/smartctl/$(basename "$3").json"`, "smartctl"},
+		Devices: []string{"/dev/sda", "/dev/nvme0n1", "/dev/permission", "/dev/missing"},
+	}))
+
+	var s *models.System
+	for i := 0; i < 100; i++ {
+		s = models.NewSystem()
+		collectSMARTData(s)
+		if len(s.Custom) > 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Equal(t, "PASSED", s.Custom["smart_sda_health"])
+	assert.Equal(t, int64(12), s.Custom["smart_sda_reallocated_sectors"])
+	assert.Equal(t, 13, s.Custom["smart_sda_wear_percent"])
+	assert.Equal(t, 3, s.Custom["smart_nvme0n1_wear_percent"])
+	assert.Equal(t, int64(0), s.Custom["smart_nvme0n1_media_errors"])
+	assert.Contains(t, s.Custom["smart_error"], "/dev/permission: smartctl exit status 2")
+	assert.Contains(t, s.Custom["smart_error"], "/dev/missing: exit status 1: cat:")
+
+	assert.Error(t, configureSMART(&models.SMARTConfig{Timeout: -1}))
+}
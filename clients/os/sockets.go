@@ -0,0 +1,157 @@
+package os
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	prevNetSNMP     map[string]uint64
+	prevNetSNMPTime time.Time
+)
+
+// tcpStates maps the hex "st" column of /proc/net/tcp to the state names used as keys
+var tcpStates = map[string]string{
+	"01": "established",
+	"02": "syn_sent",
+	"03": "syn_recv",
+	"04": "fin_wait1",
+	"05": "fin_wait2",
+	"06": "time_wait",
+	"07": "close",
+	"08": "close_wait",
+	"09": "last_ack",
+	"0A": "listen",
+	"0B": "closing",
+	"0C": "new_syn_recv",
+}
+
+// udpUnconnected is the state of UDP sockets that are bound but not connected, i.e. listening
+const udpUnconnected = "07"
+
+// countSocketStates counts the sockets in /proc/net/{tcp,tcp6,udp,udp6} style files by their state column
+func countSocketStates(paths ...string) map[string]int {
+	counts := make(map[string]int)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // Header
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) > 3 {
+				counts[strings.ToUpper(fields[3])]++
+			}
+		}
+		file.Close()
+	}
+	return counts
+}
+
+// parseNetSNMP parses the header/value line pairs of /proc/net/snmp and /proc/net/netstat, e.g.
+//
+//	Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens ...
+//	Tcp: 1 200 120000 -1 4242 ...
+//
+// into "Tcp.ActiveOpens" style keys. Negative values (MaxConn) are skipped.
+func parseNetSNMP(paths ...string) map[string]uint64 {
+	counters := make(map[string]uint64)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		var header []string
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 {
+				continue
+			}
+
+			// The value line follows a header line with the same prefix
+			if header == nil || header[0] != fields[0] || len(header) != len(fields) {
+				header = fields
+				continue
+			}
+			protocol := strings.TrimSuffix(fields[0], ":")
+			for i := 1; i < len(fields); i++ {
+				if value, err := strconv.ParseUint(fields[i], 10, 64); err == nil {
+					counters[protocol+"."+header[i]] = value
+				}
+			}
+			header = nil
+		}
+		file.Close()
+	}
+	return counters
+}
+
+// collectSocketData reports TCP connections by state, listening sockets, and
+// TCP segment, retransmission and UDP error rates
+func collectSocketData(s *models.System) {
+	tcp := countSocketStates(filepath.Join(procRoot, "net/tcp"), filepath.Join(procRoot, "net/tcp6"))
+	for code, state := range tcpStates {
+		s.Custom["tcp_"+state] = tcp[code]
+	}
+	udp := countSocketStates(filepath.Join(procRoot, "net/udp"), filepath.Join(procRoot, "net/udp6"))
+	s.Custom["udp_listen"] = udp[udpUnconnected]
+
+	now := time.Now()
+	counters := parseNetSNMP(filepath.Join(procRoot, "net/snmp"), filepath.Join(procRoot, "net/netstat"))
+	if len(counters) == 0 {
+		return
+	}
+
+	if prevNetSNMP != nil {
+		if elapsed := now.Sub(prevNetSNMPTime).Seconds(); elapsed > 0 {
+			delta := func(counter string) (float64, bool) {
+				cur, ok := counters[counter]
+				prev, okPrev := prevNetSNMP[counter]
+				if !ok || !okPrev || cur < prev {
+					return 0, false
+				}
+				return float64(cur - prev), true
+			}
+
+			rates := map[string]string{
+				"Tcp.InSegs":         "tcp_in_segs_rate",
+				"Tcp.OutSegs":        "tcp_out_segs_rate",
+				"Tcp.RetransSegs":    "tcp_retrans_rate",
+				"Tcp.InErrs":         "tcp_in_errors_rate",
+				"TcpExt.TCPTimeouts": "tcp_timeouts_rate",
+				"TcpExt.ListenDrops": "tcp_listen_drops_rate",
+				"Udp.InDatagrams":    "udp_in_rate",
+				"Udp.OutDatagrams":   "udp_out_rate",
+				"Udp.InErrors":       "udp_in_errors_rate",
+				"Udp.RcvbufErrors":   "udp_rcvbuf_errors_rate",
+				"Udp.NoPorts":        "udp_no_ports_rate",
+			}
+			for counter, key := range rates {
+				if value, ok := delta(counter); ok {
+					s.Custom[key] = fmt.Sprintf("%.2f/s", value/elapsed)
+				}
+			}
+
+			// Share of sent segments that were retransmissions, the usual sign of a lossy link
+			retrans, ok1 := delta("Tcp.RetransSegs")
+			out, ok2 := delta("Tcp.OutSegs")
+			if ok1 && ok2 && out > 0 {
+				s.Custom["tcp_retrans_percent"] = fmt.Sprintf("%.2f%%", retrans/out*100)
+			}
+		}
+	}
+
+	prevNetSNMP = counters
+	prevNetSNMPTime = now
+}
@@ -0,0 +1,69 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCountSocketStates(t *testing.T) {
+	counts := countSocketStates("testdata/proc/net/tcp", "testdata/proc/net/tcp6", "testdata/proc/net/missing")
+
+	assert.Equal(t, map[string]int{"0A": 3, "01": 2, "06": 1, "08": 1}, counts)
+}
+
+func TestParseNetSNMP(t *testing.T) {
+	counters := parseNetSNMP("testdata/proc/net/snmp", "testdata/proc/net/netstat")
+
+	assert.Equal(t, uint64(1700000), counters["Tcp.InSegs"])
+	assert.Equal(t, uint64(3000), counters["Tcp.RetransSegs"])
+	assert.Equal(t, uint64(12), counters["Udp.InErrors"])
+	assert.Equal(t, uint64(0), counters["UdpLite.InErrors"])
+	assert.Equal(t, uint64(250), counters["TcpExt.TCPTimeouts"])
+	assert.Equal(t, uint64(310), counters["IpExt.OutMcastPkts"])
+
+	// MaxConn is -1
+	assert.NotContains(t, counters, "Tcp.MaxConn")
+}
+
+// parseRate parses a "12.34/s" rate
+func parseRate(t *testing.T, value interface{}) float64 {
+	var rate float64
+	_, err := fmt.Sscanf(fmt.Sprint(value), "%f/s", &rate)
+	require.NoError(t, err)
+	return rate
+}
+
+func TestCollectSocketData(t *testing.T) {
+	procRoot = "testdata/proc"
+	defer func() {
+		procRoot = "/proc"
+		prevNetSNMP = nil
+	}()
+
+	// Two seconds ago 100 fewer segments were sent, 10 of them retransmitted
+	prevNetSNMP = parseNetSNMP("testdata/proc/net/snmp", "testdata/proc/net/netstat")
+	prevNetSNMP["Tcp.OutSegs"] -= 100
+	prevNetSNMP["Tcp.RetransSegs"] -= 10
+	prevNetSNMP["Udp.InErrors"] -= 4
+	prevNetSNMPTime = time.Now().Add(-2 * time.Second)
+
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectSocketData(s)
+
+	assert.Equal(t, 2, s.Custom["tcp_established"])
+	assert.Equal(t, 3, s.Custom["tcp_listen"])
+	assert.Equal(t, 1, s.Custom["tcp_time_wait"])
+	assert.Equal(t, 1, s.Custom["tcp_close_wait"])
+	assert.Equal(t, 0, s.Custom["tcp_syn_sent"])
+	assert.Equal(t, 2, s.Custom["udp_listen"])
+
+	assert.InDelta(t, 50.0, parseRate(t, s.Custom["tcp_out_segs_rate"]), 1)
+	assert.InDelta(t, 5.0, parseRate(t, s.Custom["tcp_retrans_rate"]), 0.1)
+	assert.InDelta(t, 2.0, parseRate(t, s.Custom["udp_in_errors_rate"]), 0.1)
+	assert.Equal(t, "0.00/s", s.Custom["tcp_in_segs_rate"])
+	assert.Equal(t, "10.00%", s.Custom["tcp_retrans_percent"])
+}
@@ -0,0 +1,117 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTextfileNamespace = "textfile"
+	maxMetricsSize           = 1 << 20 // Bytes read from a metrics file or command output
+)
+
+var textfileConfig *models.TextfileConfig
+
+// configureTextfile validates and applies the textfile directory from the config
+func configureTextfile(config *models.TextfileConfig) error {
+	if config == nil {
+		textfileConfig = nil
+		return nil
+	}
+	if config.Directory == "" {
+		return fmt.Errorf("textfile directory is required")
+	}
+
+	applied := *config
+	if applied.Namespace == "" {
+		applied.Namespace = defaultTextfileNamespace
+	}
+	if !metricNamePattern.MatchString(applied.Namespace) {
+		return fmt.Errorf("textfile namespace %q must be lowercase letters, digits and underscores", applied.Namespace)
+	}
+	textfileConfig = &applied
+	return nil
+}
+
+// readMetricsFile reads a metrics file, failing if it is larger than maxMetricsSize
+func readMetricsFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMetricsSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxMetricsSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxMetricsSize)
+	}
+	return data, nil
+}
+
+// collectTextfileData merges the metrics of every *.prom and *.json file in the textfile
+// directory into the sample. Prometheus metrics become <namespace>_<metric>, JSON keys
+// <namespace>_<file>_<key>. Invalid files are skipped and reported as
+// <namespace>_error_<file>_<extension>, with the number of them in <namespace>_errors.
+func collectTextfileData(s *models.System) {
+	config := textfileConfig
+	if config == nil {
+		return
+	}
+	namespace := config.Namespace
+
+	entries, err := os.ReadDir(config.Directory)
+	if err != nil {
+		s.Custom[namespace+"_errors"] = 1
+		s.Custom[namespace+"_error"] = err.Error()
+		return
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".prom" || ext == ".json") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	errors := 0
+	for _, file := range files {
+		ext := filepath.Ext(file)
+		stem := utils.SanitizeMetricKey(strings.TrimSuffix(file, ext))
+
+		data, err := readMetricsFile(filepath.Join(config.Directory, file))
+		var metrics map[string]interface{}
+		if err == nil {
+			if ext == ".prom" {
+				metrics, err = parsePromText(data)
+			} else {
+				metrics, err = parseJSONMetrics(data)
+			}
+		}
+		if err != nil {
+			errors++
+			// The extension keeps foo.prom and foo.json apart
+			s.Custom[fmt.Sprintf("%s_error_%s", namespace, utils.SanitizeMetricKey(file))] = fmt.Sprintf("%s: %v", file, err)
+			continue
+		}
+
+		prefix := namespace + "_"
+		if ext == ".json" {
+			prefix += stem + "_"
+		}
+		for key, value := range metrics {
+			s.Custom[prefix+key] = value
+		}
+	}
+	s.Custom[namespace+"_errors"] = errors
+}
@@ -0,0 +1,59 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConfigureTextfile(t *testing.T) {
+	defer configureTextfile(nil)
+
+	require.NoError(t, configureTextfile(&models.TextfileConfig{Directory: "testdata/textfile"}))
+	assert.Equal(t, "textfile", textfileConfig.Namespace)
+
+	assert.Error(t, configureTextfile(&models.TextfileConfig{}))
+	assert.Error(t, configureTextfile(&models.TextfileConfig{Directory: "testdata/textfile", Namespace: "My Scripts"}))
+}
+
+func TestCollectTextfileData(t *testing.T) {
+	require.NoError(t, configureTextfile(&models.TextfileConfig{Directory: "testdata/textfile", Namespace: "scripts"}))
+	defer configureTextfile(nil)
+
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectTextfileData(s)
+
+	assert.Equal(t, 3600.0, s.Custom["scripts_backup_age_seconds_nas"])
+	assert.Equal(t, 86400.0, s.Custom["scripts_backup_age_seconds_photos"])
+	assert.Equal(t, 1.5e9, s.Custom["scripts_backup_size_bytes"])
+	assert.NotContains(t, s.Custom, "scripts_backup_ratio")
+
+	assert.Equal(t, 143.0, s.Custom["scripts_game_fps"])
+	assert.Equal(t, "Counter-Strike 2", s.Custom["scripts_game_title"])
+	assert.Equal(t, true, s.Custom["scripts_game_vulkan"])
+	assert.Equal(t, 97.5, s.Custom["scripts_game_gpu_load"])
+
+	// Invalid files are reported and none of their metrics are used
+	assert.Equal(t, 3, s.Custom["scripts_errors"])
+	assert.Equal(t, `broken.prom: line 2: invalid value "metric"`, s.Custom["scripts_error_broken_prom"])
+	assert.Contains(t, s.Custom["scripts_error_broken_json"], "broken.json: ")
+	assert.Contains(t, s.Custom["scripts_error_bad_json"], "bad.json: ")
+	assert.NotContains(t, s.Custom, "scripts_good_metric")
+
+	// The result is still a valid payload
+	_, err := json.Marshal(s.ToMap())
+	assert.NoError(t, err)
+}
+
+func TestCollectTextfileDataMissingDirectory(t *testing.T) {
+	require.NoError(t, configureTextfile(&models.TextfileConfig{Directory: "testdata/missing"}))
+	defer configureTextfile(nil)
+
+	s := &models.System{Custom: make(map[string]interface{})}
+	collectTextfileData(s)
+
+	assert.Equal(t, 1, s.Custom["textfile_errors"])
+	assert.Contains(t, s.Custom, "textfile_error")
+}
@@ -0,0 +1,197 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Causes of a throttling episode, from the most to the least severe
+const (
+	ThrottleCrit              = "crit"
+	ThrottleMax               = "max"
+	ThrottleCounter           = "cpu_throttle"
+	ThrottleFrequencyCollapse = "frequency_collapse"
+)
+
+// The CPU counts as throttled when its busy cores run well below their maximum frequency
+const (
+	collapseFrequencyRatio = 0.5
+	collapseMinCPUUsage    = 50.0
+)
+
+// throttleEpisode is a throttling episode that hasn't ended yet
+type throttleEpisode struct {
+	Cause    string
+	Source   string // Sensor ID, or "cpu" for the cpufreq based causes
+	Start    time.Time
+	PeakTemp float64 // 0 when no temperature is known
+}
+
+var (
+	throttleEpisodes = map[string]*throttleEpisode{}
+
+	// Sum of the core and package throttle counters of the previous sample, nil before the first one
+	prevThrottleCount *uint64
+)
+
+// sumThrottleCounts adds up the thermal throttle counters of all CPUs.
+// Returns false if the platform doesn't expose them.
+func sumThrottleCounts(freqs []cpuFreq) (uint64, bool) {
+	var total uint64
+	found := false
+	for _, freq := range freqs {
+		if freq.CoreThrottleCount != nil {
+			total += *freq.CoreThrottleCount
+			found = true
+		}
+		if freq.PackageThrottleCount != nil {
+			total += *freq.PackageThrottleCount
+			found = true
+		}
+	}
+	return total, found
+}
+
+// frequencyCollapsed reports whether the average frequency of the busy cores fell below
+// collapseFrequencyRatio of their maximum. Idle cores are left out, they clock down to save power.
+func frequencyCollapsed(freqs []cpuFreq, coreUsage map[int]float64) bool {
+	var cur, max float64
+	for _, freq := range freqs {
+		if freq.MaxMHz > 0 && coreUsage[freq.CPU] >= collapseMinCPUUsage {
+			cur += freq.CurMHz
+			max += freq.MaxMHz
+		}
+	}
+	return max > 0 && cur < max*collapseFrequencyRatio
+}
+
+// parseCoreUsage reads the per-core usage reported by collectCPUData, by CPU number
+func parseCoreUsage(cores map[string]string) map[int]float64 {
+	usage := make(map[int]float64, len(cores))
+	for key, value := range cores {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(key, "cpu_core_"))
+		if err != nil {
+			continue
+		}
+		if percent, err := strconv.ParseFloat(value, 64); err == nil {
+			usage[cpu] = percent
+		}
+	}
+	return usage
+}
+
+// throttleMessage describes an ended episode
+func throttleMessage(episode *throttleEpisode, duration time.Duration) string {
+	var cause string
+	switch episode.Cause {
+	case ThrottleCrit:
+		cause = fmt.Sprintf("%s above its critical temperature", episode.Source)
+	case ThrottleMax:
+		cause = fmt.Sprintf("%s above its maximum temperature", episode.Source)
+	case ThrottleCounter:
+		cause = "CPU thermal throttling"
+	case ThrottleFrequencyCollapse:
+		cause = "CPU frequency collapse under load"
+	}
+	message := fmt.Sprintf("%s for %s", cause, duration)
+	if episode.PeakTemp > 0 {
+		message += fmt.Sprintf(", peaking at %.1f°C", episode.PeakTemp)
+	}
+	return message
+}
+
+// detectThrottling updates the running episodes with the current readings and returns an
+// event for every episode that ended. coreUsage is the usage of every core by CPU number,
+// cpuTemp is 0 when unknown.
+func detectThrottling(sensors []models.Sensor, freqs []cpuFreq, coreUsage map[int]float64, cpuTemp float64, now time.Time) []models.Event {
+	type activeCause struct {
+		cause, source string
+		temp          float64
+	}
+	active := map[string]activeCause{}
+
+	for _, sensor := range sensors {
+		if sensor.Kind != "temp" {
+			continue
+		}
+		key := "sensor:" + sensor.ID
+		if sensor.Crit > 0 && sensor.Value >= sensor.Crit {
+			active[key] = activeCause{ThrottleCrit, sensor.ID, sensor.Value}
+		} else if sensor.Max > 0 && sensor.Value >= sensor.Max {
+			active[key] = activeCause{ThrottleMax, sensor.ID, sensor.Value}
+		}
+	}
+
+	// The counters only ever grow, any increase means the CPU throttled since the last sample
+	if count, ok := sumThrottleCounts(freqs); ok {
+		if prevThrottleCount != nil && count > *prevThrottleCount {
+			active[ThrottleCounter] = activeCause{ThrottleCounter, "cpu", cpuTemp}
+		}
+		prevThrottleCount = &count
+	}
+
+	if frequencyCollapsed(freqs, coreUsage) {
+		active[ThrottleFrequencyCollapse] = activeCause{ThrottleFrequencyCollapse, "cpu", cpuTemp}
+	}
+
+	for key, current := range active {
+		episode, ok := throttleEpisodes[key]
+		if !ok {
+			episode = &throttleEpisode{Cause: current.cause, Source: current.source, Start: now}
+			throttleEpisodes[key] = episode
+		}
+		// A sensor passing its critical threshold escalates the episode
+		if current.cause == ThrottleCrit {
+			episode.Cause = ThrottleCrit
+		}
+		if current.temp > episode.PeakTemp {
+			episode.PeakTemp = current.temp
+		}
+	}
+
+	// Sorted so events of the same sample are reported in a stable order
+	var ended []string
+	for key := range throttleEpisodes {
+		if _, ok := active[key]; !ok {
+			ended = append(ended, key)
+		}
+	}
+	sort.Strings(ended)
+
+	var events []models.Event
+	for _, key := range ended {
+		episode := throttleEpisodes[key]
+		delete(throttleEpisodes, key)
+
+		duration := now.Sub(episode.Start).Round(time.Second)
+		details := map[string]string{
+			"cause":    episode.Cause,
+			"source":   episode.Source,
+			"start":    strconv.FormatInt(episode.Start.Unix(), 10),
+			"end":      strconv.FormatInt(now.Unix(), 10),
+			"duration": duration.String(),
+		}
+		if episode.PeakTemp > 0 {
+			details["peak_temp"] = fmt.Sprintf("%.1f", episode.PeakTemp)
+		}
+
+		event := models.NewEvent("throttling", throttleMessage(episode, duration), details)
+		event.Timestamp = episode.Start.Unix()
+		events = append(events, event)
+	}
+	return events
+}
+
+// collectThrottlingData reports throttling episodes as events once they end, and the
+// number of episodes still running. Runs after the temperature and CPU collectors.
+func collectThrottlingData(s *models.System) {
+	cpuTemp, _ := strconv.ParseFloat(strings.TrimSuffix(s.CPUTemp, "°C"), 64)
+
+	events := detectThrottling(s.Sensors, readCPUFreqs(), parseCoreUsage(s.CPUCores), cpuTemp, time.Now())
+	s.Events = append(s.Events, events...)
+	s.Custom["throttling_active"] = len(throttleEpisodes)
+}
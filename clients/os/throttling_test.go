@@ -0,0 +1,125 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func resetThrottling() {
+	throttleEpisodes = map[string]*throttleEpisode{}
+	prevThrottleCount = nil
+}
+
+func TestDetectThrottlingSensor(t *testing.T) {
+	defer resetThrottling()
+	start := time.Unix(1700000000, 0)
+
+	sensor := models.Sensor{ID: "k10temp/temp1", Kind: "temp", Value: 80, Max: 90, Crit: 100}
+	assert.Empty(t, detectThrottling([]models.Sensor{sensor}, nil, nil, 0, start))
+	assert.Empty(t, throttleEpisodes)
+
+	// Crossing max starts an episode, crossing crit escalates it
+	sensor.Value = 92
+	assert.Empty(t, detectThrottling([]models.Sensor{sensor}, nil, nil, 0, start.Add(time.Second)))
+	sensor.Value = 101
+	assert.Empty(t, detectThrottling([]models.Sensor{sensor}, nil, nil, 0, start.Add(2*time.Second)))
+	sensor.Value = 95
+	assert.Empty(t, detectThrottling([]models.Sensor{sensor}, nil, nil, 0, start.Add(3*time.Second)))
+
+	sensor.Value = 85
+	events := detectThrottling([]models.Sensor{sensor}, nil, nil, 0, start.Add(31*time.Second))
+	require.Len(t, events, 1)
+	assert.Equal(t, "throttling", events[0].Type)
+	assert.Equal(t, start.Add(time.Second).Unix(), events[0].Timestamp)
+	assert.Equal(t, "k10temp/temp1 above its critical temperature for 30s, peaking at 101.0°C", events[0].Message)
+	assert.Equal(t, map[string]string{
+		"cause":     ThrottleCrit,
+		"source":    "k10temp/temp1",
+		"start":     "1700000001",
+		"end":       "1700000031",
+		"duration":  "30s",
+		"peak_temp": "101.0",
+	}, events[0].Details)
+	assert.Empty(t, throttleEpisodes)
+}
+
+func TestDetectThrottlingCPU(t *testing.T) {
+	defer resetThrottling()
+	start := time.Unix(1700000000, 0)
+
+	freqs := func(cur float64, count uint64) []cpuFreq {
+		return []cpuFreq{
+			{CPU: 0, CurMHz: cur, MaxMHz: 4000, PackageThrottleCount: &count},
+			{CPU: 1, CurMHz: cur, MaxMHz: 4000},
+		}
+	}
+
+	busy := map[int]float64{0: 90, 1: 90}
+	idle := map[int]float64{0: 10, 1: 5}
+
+	// The first sample only records the counters
+	assert.Empty(t, detectThrottling(nil, freqs(3800, 10), busy, 70, start))
+	assert.Empty(t, throttleEpisodes)
+
+	assert.Empty(t, detectThrottling(nil, freqs(3800, 12), busy, 88, start.Add(time.Second)))
+	assert.Contains(t, throttleEpisodes, ThrottleCounter)
+
+	// The counter stopped growing but the CPU is busy at a fraction of its maximum frequency
+	events := detectThrottling(nil, freqs(1200, 12), busy, 95, start.Add(2*time.Second))
+	require.Len(t, events, 1)
+	assert.Equal(t, ThrottleCounter, events[0].Details["cause"])
+	assert.Equal(t, "88.0", events[0].Details["peak_temp"])
+	assert.Equal(t, "CPU thermal throttling for 1s, peaking at 88.0°C", events[0].Message)
+
+	// A low frequency while idle is power saving, not throttling
+	events = detectThrottling(nil, freqs(1200, 12), idle, 0, start.Add(5*time.Second))
+	require.Len(t, events, 1)
+	assert.Equal(t, ThrottleFrequencyCollapse, events[0].Details["cause"])
+	assert.Equal(t, "3s", events[0].Details["duration"])
+	assert.Empty(t, throttleEpisodes)
+}
+
+func TestFrequencyCollapsedHalfIdle(t *testing.T) {
+	// Four cores busy at 75% of their maximum, the other four idle at the minimum
+	var freqs []cpuFreq
+	usage := map[int]float64{}
+	for cpu := 0; cpu < 8; cpu++ {
+		freq := cpuFreq{CPU: cpu, CurMHz: 3600, MinMHz: 800, MaxMHz: 4800}
+		usage[cpu] = 98
+		if cpu%2 == 1 {
+			freq.CurMHz = 800
+			usage[cpu] = 2
+		}
+		freqs = append(freqs, freq)
+	}
+	// The average over all cores is below half the maximum, the busy cores are fine
+	assert.False(t, frequencyCollapsed(freqs, usage))
+
+	// The busy cores clocking down is a collapse
+	for i := 0; i < len(freqs); i += 2 {
+		freqs[i].CurMHz = 1800
+	}
+	assert.True(t, frequencyCollapsed(freqs, usage))
+
+	// Without busy cores there is nothing to compare
+	assert.False(t, frequencyCollapsed(freqs, map[int]float64{}))
+}
+
+func TestParseCoreUsage(t *testing.T) {
+	assert.Equal(t, map[int]float64{0: 12.5, 1: 98},
+		parseCoreUsage(map[string]string{"cpu_core_0": "12.50", "cpu_core_1": "98.00", "cpu_core_x": "1"}))
+}
+
+func TestCollectThrottlingData(t *testing.T) {
+	sysRoot = "testdata/sys"
+	defer func() { sysRoot = "/sys" }()
+	defer resetThrottling()
+
+	s := &models.System{Custom: map[string]interface{}{}, CPUUsage: "12.00%", CPUTemp: "45.00°C"}
+	collectThrottlingData(s)
+	assert.Empty(t, s.Events)
+	assert.Equal(t, 0, s.Custom["throttling_active"])
+}
@@ -0,0 +1,325 @@
+package os
+
+import (
+	"context"
+	"device-chronicle-client/models"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	defaultUpdatesInterval = time.Hour
+	defaultUpdatesTimeout  = 5 * time.Minute
+)
+
+// countCommand counts pending packages. Configured commands print the count, the defaults
+// of the package managers list the packages and match picks the lines to count.
+type countCommand struct {
+	command  []string
+	match    func(line string) bool // nil when the command prints the count
+	okStatus []int                  // Exit statuses besides 0 that still mean the packages were listed
+}
+
+// packageManager holds the default update commands of a package manager
+type packageManager struct {
+	binary   string
+	updates  countCommand
+	security countCommand
+	reboot   []string
+}
+
+// nonEmptyLine counts every line that lists a package
+func nonEmptyLine(line string) bool {
+	return strings.TrimSpace(line) != ""
+}
+
+// dnfListsPackage reports whether a line of dnf check-update lists a package, those start
+// with the package name while continuation lines are indented
+func dnfListsPackage(line string) bool {
+	return line != "" && (unicode.IsLetter(rune(line[0])) || unicode.IsDigit(rune(line[0])))
+}
+
+// aptUpgrade simulates a dist-upgrade, a plain upgrade leaves out upgrades that pull in new
+// packages such as the kernel meta-packages on Ubuntu
+var aptUpgrade = []string{"apt-get", "-s", "-o", "Debug::NoLocking=true", "dist-upgrade"}
+
+// packageManagers are detected in this order, the first one found on the PATH is used.
+// Arch has no security metadata and needs-restarting only ships with dnf.
+var packageManagers = []packageManager{
+	{
+		binary: "apt-get",
+		updates: countCommand{
+			command: aptUpgrade,
+			match:   func(line string) bool { return strings.HasPrefix(line, "Inst ") },
+		},
+		security: countCommand{
+			command: aptUpgrade,
+			match: func(line string) bool {
+				return strings.HasPrefix(line, "Inst ") && strings.Contains(strings.ToLower(line), "security")
+			},
+		},
+	},
+	{
+		binary: "dnf",
+		// check-update exits with 100 when updates are pending and 1 on errors
+		updates: countCommand{
+			command:  []string{"dnf", "-q", "check-update"},
+			match:    dnfListsPackage,
+			okStatus: []int{100},
+		},
+		security: countCommand{
+			command: []string{"dnf", "-q", "updateinfo", "list", "--security"},
+			match:   nonEmptyLine,
+		},
+		reboot: []string{"dnf", "needs-restarting", "-r"},
+	},
+	{
+		binary: "pacman",
+		// checkupdates exits with 2 when there are no updates and 1 on errors
+		updates: countCommand{
+			command:  []string{"checkupdates"},
+			match:    nonEmptyLine,
+			okStatus: []int{2},
+		},
+	},
+}
+
+// rebootRequiredFile is created by Debian and Ubuntu packages that need a reboot
+var rebootRequiredFile = "/var/run/reboot-required"
+
+// updatesResult is the outcome of an updates check, nil fields weren't checked or failed
+type updatesResult struct {
+	Pending        *int
+	Security       *int
+	RebootRequired *bool
+	Err            string
+}
+
+// updatesChecker runs the configured update commands on their schedule and keeps the last result
+type updatesChecker struct {
+	updates  countCommand
+	security countCommand
+	reboot   []string
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	running bool
+	lastRun time.Time
+	checked bool
+	result  updatesResult
+}
+
+var (
+	updatesMu sync.Mutex
+	updates   *updatesChecker
+)
+
+// configureUpdates applies the updates config, detecting the package manager for
+// commands it leaves out. A nil config turns the check off.
+func configureUpdates(config *models.UpdatesConfig) error {
+	var checker *updatesChecker
+	if config != nil {
+		if config.Interval < 0 || config.Timeout < 0 {
+			return fmt.Errorf("updates interval and timeout can't be negative")
+		}
+		checker = &updatesChecker{
+			updates:  countCommand{command: config.UpdatesCommand},
+			security: countCommand{command: config.SecurityCommand},
+			reboot:   config.RebootCommand,
+			interval: defaultUpdatesInterval,
+			timeout:  defaultUpdatesTimeout,
+		}
+		if config.Interval > 0 {
+			checker.interval = time.Duration(config.Interval) * time.Second
+		}
+		if config.Timeout > 0 {
+			checker.timeout = time.Duration(config.Timeout) * time.Second
+		}
+
+		for _, manager := range packageManagers {
+			if _, err := exec.LookPath(manager.binary); err != nil {
+				continue
+			}
+			if checker.updates.command == nil {
+				checker.updates = manager.updates
+			}
+			if checker.security.command == nil {
+				checker.security = manager.security
+			}
+			if checker.reboot == nil {
+				checker.reboot = manager.reboot
+			}
+			break
+		}
+	}
+
+	updatesMu.Lock()
+	defer updatesMu.Unlock()
+	updates = checker
+	return nil
+}
+
+// runCommand runs a command and returns its output. Commands are run with the C locale
+// so the output of package managers and smartctl can be parsed.
+func runCommand(command []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.WaitDelay = time.Second
+
+	stdout := &limitedBuffer{limit: maxMetricsSize, onExceeded: cancel}
+	stderr := &limitedBuffer{limit: maxPluginStderr}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	switch {
+	case stdout.exceeded:
+		return "", fmt.Errorf("output larger than %d bytes", maxMetricsSize)
+	case ctx.Err() == context.DeadlineExceeded:
+		return "", fmt.Errorf("timed out after %s", timeout)
+	case err != nil:
+		if message := strings.TrimSpace(stderr.buf.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+	}
+	return stdout.buf.String(), err
+}
+
+// runCountCommand runs a count command. A configured command prints the count and its exit
+// status is ignored when it printed one, e.g. grep -c exits with 1 when it counted nothing.
+// The lines the package managers list are counted unless they exit with an error.
+func runCountCommand(count countCommand, timeout time.Duration) (*int, error) {
+	output, err := runCommand(count.command, timeout)
+	if count.match != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && slices.Contains(count.okStatus, exitErr.ExitCode()) {
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines := 0
+		for _, line := range strings.Split(output, "\n") {
+			if count.match(line) {
+				lines++
+			}
+		}
+		return &lines, nil
+	}
+
+	number, parseErr := strconv.Atoi(strings.TrimSpace(output))
+	if parseErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("expected a number, got %q", strings.TrimSpace(output))
+	}
+	return &number, nil
+}
+
+// checkRebootRequired reports whether the reboot-required file exists or the reboot
+// command exits with status 1. Returns nil if neither is available.
+func checkRebootRequired(command []string, timeout time.Duration) (*bool, error) {
+	required := true
+	if _, err := os.Stat(rebootRequiredFile); err == nil {
+		return &required, nil
+	}
+	if len(command) == 0 {
+		return nil, nil
+	}
+
+	_, err := runCommand(command, timeout)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		required = false
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		required = true
+	default:
+		return nil, err
+	}
+	return &required, nil
+}
+
+// check runs the update commands once and keeps the result
+func (c *updatesChecker) check() {
+	var result updatesResult
+	var errs []string
+
+	if len(c.updates.command) > 0 {
+		count, err := runCountCommand(c.updates, c.timeout)
+		if err != nil {
+			errs = append(errs, "updates: "+err.Error())
+		}
+		result.Pending = count
+	}
+	if len(c.security.command) > 0 {
+		count, err := runCountCommand(c.security, c.timeout)
+		if err != nil {
+			errs = append(errs, "security: "+err.Error())
+		}
+		result.Security = count
+	}
+	required, err := checkRebootRequired(c.reboot, c.timeout)
+	if err != nil {
+		errs = append(errs, "reboot: "+err.Error())
+	}
+	result.RebootRequired = required
+	result.Err = strings.Join(errs, "; ")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running = false
+	c.checked = true
+	c.result = result
+}
+
+// collectUpdatesData starts an updates check when one is due and reports the result of
+// the last one. Checks run in the background, package managers may take minutes.
+func collectUpdatesData(s *models.System) {
+	updatesMu.Lock()
+	checker := updates
+	updatesMu.Unlock()
+	if checker == nil {
+		return
+	}
+
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	now := time.Now()
+	if !checker.running && now.Sub(checker.lastRun) >= checker.interval {
+		checker.running = true
+		checker.lastRun = now
+		go checker.check()
+	}
+
+	// Nothing to report until the first check finished
+	if !checker.checked {
+		return
+	}
+	if checker.result.Pending != nil {
+		s.Custom["updates_pending"] = *checker.result.Pending
+	}
+	if checker.result.Security != nil {
+		s.Custom["updates_security"] = *checker.result.Security
+	}
+	if checker.result.RebootRequired != nil {
+		s.Custom["reboot_required"] = *checker.result.RebootRequired
+	}
+	if checker.result.Err != "" {
+		s.Custom["updates_error"] = checker.result.Err
+	}
+}
@@ -0,0 +1,120 @@
+package os
+
+import (
+	"device-chronicle-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForUpdates collects until the first updates check finished
+func waitForUpdates(t *testing.T) *models.System {
+	for i := 0; i < 100; i++ {
+		s := models.NewSystem()
+		collectUpdatesData(s)
+		if len(s.Custom) > 0 {
+			return s
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("updates check didn't finish")
+	return nil
+}
+
+func TestCollectUpdatesData(t *testing.T) {
+	rebootRequiredFile = filepath.Join(t.TempDir(), "reboot-required")
+	defer func() { rebootRequiredFile = "/var/run/reboot-required" }()
+	defer configureUpdates(nil)
+
+	// Nothing is checked unless configured
+	require.NoError(t, configureUpdates(nil))
+	s := models.NewSystem()
+	collectUpdatesData(s)
+	assert.Empty(t, s.Custom)
+
+	require.NoError(t, configureUpdates(&models.UpdatesConfig{
+		// grep -c exits with 1 when it counts nothing
+		UpdatesCommand:  []string{"sh", "-c", "echo 12"},
+		SecurityCommand: []string{"sh", "-c", "echo 0; exit 1"},
+		RebootCommand:   []string{"sh", "-c", "exit 1"},
+	}))
+	s = waitForUpdates(t)
+	assert.Equal(t, 12, s.Custom["updates_pending"])
+	assert.Equal(t, 0, s.Custom["updates_security"])
+	assert.Equal(t, true, s.Custom["reboot_required"])
+	assert.NotContains(t, s.Custom, "updates_error")
+
+	// Debian and Ubuntu flag a required reboot with a file
+	require.NoError(t, os.WriteFile(rebootRequiredFile, nil, 0644))
+	require.NoError(t, configureUpdates(&models.UpdatesConfig{
+		UpdatesCommand:  []string{"sh", "-c", "echo 'Could not resolve host' >&2; exit 100"},
+		SecurityCommand: []string{"sh", "-c", "echo none"},
+		RebootCommand:   []string{"sh", "-c", "exit 0"},
+	}))
+	s = waitForUpdates(t)
+	assert.NotContains(t, s.Custom, "updates_pending")
+	assert.Equal(t, true, s.Custom["reboot_required"])
+	assert.Equal(t, `updates: exit status 100: Could not resolve host; security: expected a number, got "none"`, s.Custom["updates_error"])
+
+	require.NoError(t, os.Remove(rebootRequiredFile))
+	require.NoError(t, configureUpdates(&models.UpdatesConfig{
+		UpdatesCommand: []string{"sh", "-c", "echo 1"},
+		RebootCommand:  []string{"sh", "-c", "exit 0"},
+		Timeout:        1,
+	}))
+	s = waitForUpdates(t)
+	assert.Equal(t, false, s.Custom["reboot_required"])
+
+	assert.Error(t, configureUpdates(&models.UpdatesConfig{Interval: -1}))
+}
+
+func TestPackageManagerCounts(t *testing.T) {
+	manager := func(binary string) packageManager {
+		for _, manager := range packageManagers {
+			if manager.binary == binary {
+				return manager
+			}
+		}
+		t.Fatalf("no package manager %s", binary)
+		return packageManager{}
+	}
+	count := func(command countCommand, script string) (*int, error) {
+		command.command = []string{"sh", "-c", script}
+		return runCountCommand(command, 5*time.Second)
+	}
+
+	apt := manager("apt-get")
+	assert.Contains(t, apt.updates.command, "dist-upgrade")
+	listing := `printf 'Inst linux-generic [6.8.0.45] (6.8.0.47 Ubuntu:24.04/noble-security [amd64])\nInst curl [8.5.0-2] (8.5.0-3 Ubuntu:24.04/noble-updates [amd64])\nConf curl (8.5.0-3)\n'`
+	pending, err := count(apt.updates, listing)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *pending)
+	pending, err = count(apt.security, listing)
+	require.NoError(t, err)
+	assert.Equal(t, 1, *pending)
+	// apt failing to read its lists isn't "up to date"
+	_, err = count(apt.updates, "echo 'E: Could not open lock file' >&2; exit 100")
+	assert.ErrorContains(t, err, "Could not open lock file")
+
+	// dnf check-update exits with 100 when updates are pending
+	dnf := manager("dnf")
+	pending, err = count(dnf.updates, `printf 'kernel.x86_64  6.10.3-200.fc40  updates\n    continued\nvim-enhanced.x86_64  2:9.1.0-1.fc40  updates\n'; exit 100`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *pending)
+	pending, err = count(dnf.updates, "exit 0")
+	require.NoError(t, err)
+	assert.Equal(t, 0, *pending)
+	_, err = count(dnf.updates, "echo 'Failed to download metadata' >&2; exit 1")
+	assert.ErrorContains(t, err, "Failed to download metadata")
+
+	// checkupdates exits with 2 when there are no updates
+	pacman := manager("pacman")
+	pending, err = count(pacman.updates, "exit 2")
+	require.NoError(t, err)
+	assert.Equal(t, 0, *pending)
+	_, err = count(pacman.updates, "echo 'Cannot fetch updates' >&2; exit 1")
+	assert.ErrorContains(t, err, "Cannot fetch updates")
+}
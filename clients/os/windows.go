@@ -273,6 +273,8 @@ func collectWindowsHostData(s *models.System) {
 		minutes := int(uptimeDuration.Minutes()) % 60
 
 		s.Uptime = fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+		s.BootTime = int64(hostInfo.BootTime)
+		s.UptimeSeconds = hostInfo.Uptime
 
 		// Add OS version information
 		s.Custom["os_version"] = fmt.Sprintf("Windows %s", hostInfo.PlatformVersion)
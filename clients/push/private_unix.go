@@ -0,0 +1,28 @@
+//go:build !windows
+
+package push
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir makes sure only the current user can enter dir. It must be a real
+// directory owned by the user, another user creating it first in /tmp would control it.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s isn't a directory, the socket needs a private directory", dir)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); !ok || int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s belongs to another user, the socket needs a private directory", dir)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("%s can be entered by other users, the socket needs a private directory", dir)
+	}
+	return nil
+}
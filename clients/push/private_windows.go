@@ -0,0 +1,7 @@
+package push
+
+// checkPrivateDir does nothing on Windows, which doesn't report directory owners and
+// permissions the way unix does
+func checkPrivateDir(dir string) error {
+	return nil
+}
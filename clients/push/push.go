@@ -0,0 +1,312 @@
+package push
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"device-chronicle-client/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MaxPendingMetrics = 1000 // Metrics kept until the next sample, further ones are refused
+	MaxPendingEvents  = 100  // Events kept until the next sample, the oldest are dropped
+	maxLineSize       = 64 * 1024
+)
+
+// Message is what local scripts send to the agent, one per line. A line is either a JSON
+// object or whitespace separated key=value metrics, e.g. "game_fps=143 gpu_load=97".
+type Message struct {
+	Metrics map[string]interface{} `json:"metrics,omitempty"`
+	Tags    map[string]string      `json:"tags,omitempty"`
+	Events  []models.Event         `json:"events,omitempty"`
+}
+
+// SocketPath returns where the agent listens, $XDG_RUNTIME_DIR/chronicle.sock or a socket
+// in a per-user directory in the temp directory when XDG_RUNTIME_DIR is not set
+func SocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "chronicle.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("chronicle-%d", os.Getuid()), "chronicle.sock")
+}
+
+// parseValue keeps numbers and booleans typed so they can be charted
+func parseValue(value string) interface{} {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	if boolean, err := strconv.ParseBool(value); err == nil {
+		return boolean
+	}
+	return value
+}
+
+// ParseLine parses a line sent to the socket
+func ParseLine(line string) (Message, error) {
+	var message Message
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			return message, err
+		}
+	} else {
+		message.Metrics = make(map[string]interface{})
+		for _, field := range strings.Fields(line) {
+			key, value, found := strings.Cut(field, "=")
+			if !found || key == "" {
+				return message, fmt.Errorf("%q is not key=value", field)
+			}
+			message.Metrics[key] = parseValue(value)
+		}
+	}
+
+	for i, event := range message.Events {
+		if event.Type == "" {
+			return message, errors.New("event type is required")
+		}
+		if event.Timestamp == 0 {
+			message.Events[i].Timestamp = time.Now().Unix()
+		}
+	}
+	if len(message.Metrics) == 0 && len(message.Tags) == 0 && len(message.Events) == 0 {
+		return message, errors.New("nothing to push")
+	}
+	return message, nil
+}
+
+// ParseArgs parses the arguments of "chronicle-client push", e.g.
+//
+//	game_fps=143 --tag game=cs2
+//	--event render_started --message "Render started" --tag scene=city
+func ParseArgs(args []string) (Message, error) {
+	message := Message{Metrics: make(map[string]interface{}), Tags: make(map[string]string)}
+	var eventType, eventMessage string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			key, value, found := strings.Cut(arg, "=")
+			if !found || key == "" {
+				return message, fmt.Errorf("%q is not key=value", arg)
+			}
+			message.Metrics[key] = parseValue(value)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !hasValue {
+			if i+1 >= len(args) {
+				return message, fmt.Errorf("%s needs a value", arg)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "tag":
+			key, tagValue, found := strings.Cut(value, "=")
+			if !found || key == "" {
+				return message, fmt.Errorf("tag %q is not key=value", value)
+			}
+			message.Tags[key] = tagValue
+		case "event":
+			eventType = value
+		case "message":
+			eventMessage = value
+		default:
+			return message, fmt.Errorf("unknown flag %s", arg)
+		}
+	}
+
+	if eventType != "" {
+		if eventMessage == "" {
+			eventMessage = eventType
+		}
+		message.Events = append(message.Events, models.NewEvent(utils.SanitizeMetricKey(eventType), eventMessage, message.Tags))
+	} else if eventMessage != "" {
+		return message, errors.New("--message needs --event")
+	}
+	if len(message.Metrics) == 0 && len(message.Tags) == 0 && len(message.Events) == 0 {
+		return message, errors.New("nothing to push")
+	}
+	return message, nil
+}
+
+// Send sends a message to the agent listening on path and waits for it to be accepted
+func Send(path string, message Message) error {
+	conn, err := net.DialTimeout("unix", path, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("agent not running: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if reply = strings.TrimSpace(reply); reply != "ok" {
+		return errors.New(strings.TrimPrefix(reply, "error: "))
+	}
+	return nil
+}
+
+// Server collects what local scripts push until the next sample takes it
+type Server struct {
+	listener net.Listener
+	mu       sync.Mutex
+	metrics  map[string]interface{}
+	tags     map[string]string
+	events   []models.Event
+}
+
+// Listen starts accepting pushes on a unix socket only the current user can use. The
+// socket is created in a directory only the current user can enter, its own permissions
+// depend on the umask until it is changed. A socket left behind by an agent that died is
+// replaced, a live one is an error.
+func Listen(path string) (*Server, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := checkPrivateDir(dir); err != nil {
+		return nil, err
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another agent is listening on %s", path)
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s := &Server{
+		listener: listener,
+		metrics:  make(map[string]interface{}),
+		tags:     make(map[string]string),
+	}
+	go s.accept()
+	return s, nil
+}
+
+// Close stops accepting pushes and removes the socket
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle reads lines until the script disconnects, answering each with "ok" or "error: ..."
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		message, err := ParseLine(scanner.Text())
+		if err == nil {
+			err = s.add(message)
+		}
+		reply := "ok\n"
+		if err != nil {
+			reply = "error: " + err.Error() + "\n"
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// add queues a message for the next sample, later values of a metric or tag replace earlier ones
+func (s *Server) add(message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range message.Metrics {
+		if _, ok := s.metrics[utils.SanitizeMetricKey(key)]; !ok && len(s.metrics) >= MaxPendingMetrics {
+			return fmt.Errorf("more than %d metrics pending", MaxPendingMetrics)
+		}
+	}
+	for key, value := range message.Metrics {
+		switch value.(type) {
+		case float64, string, bool:
+			s.metrics[utils.SanitizeMetricKey(key)] = value
+		}
+	}
+	for key, value := range message.Tags {
+		s.tags[key] = value
+	}
+
+	s.events = append(s.events, message.Events...)
+	if len(s.events) > MaxPendingEvents {
+		s.events = s.events[len(s.events)-MaxPendingEvents:]
+	}
+	return nil
+}
+
+// Apply moves everything pushed since the previous sample into system. Pushed metrics
+// never replace the ones the agent collected itself.
+func (s *Server) Apply(system *models.System) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if system.Custom == nil {
+		system.Custom = make(map[string]interface{})
+	}
+	collected := system.ToMap()
+	for key, value := range s.metrics {
+		if _, ok := collected[key]; !ok {
+			system.Custom[key] = value
+		}
+	}
+
+	if len(s.tags) > 0 {
+		if system.Tags == nil {
+			system.Tags = make(map[string]string)
+		}
+		for key, value := range s.tags {
+			system.Tags[key] = value
+		}
+	}
+	system.Events = append(system.Events, s.events...)
+
+	s.metrics = make(map[string]interface{})
+	s.tags = make(map[string]string)
+	s.events = nil
+}
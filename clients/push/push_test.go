@@ -0,0 +1,188 @@
+package push
+
+import (
+	"bufio"
+	"device-chronicle-client/models"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	assert.Equal(t, "/run/user/1000/chronicle.sock", SocketPath())
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	assert.Equal(t, fmt.Sprintf("chronicle-%d", os.Getuid()), filepath.Base(filepath.Dir(SocketPath())))
+}
+
+func TestParseLine(t *testing.T) {
+	message, err := ParseLine("game_fps=143 vsync=true game=cs2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"game_fps": 143.0, "vsync": true, "game": "cs2"}, message.Metrics)
+
+	message, err = ParseLine(`{"metrics":{"render_progress":42},"tags":{"scene":"city"},"events":[{"type":"render_started","message":"Render started"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"render_progress": 42.0}, message.Metrics)
+	assert.Equal(t, map[string]string{"scene": "city"}, message.Tags)
+	require.Len(t, message.Events, 1)
+	assert.NotZero(t, message.Events[0].Timestamp)
+
+	for _, invalid := range []string{"game_fps", "=143", "{", `{"events":[{"message":"no type"}]}`, `{}`} {
+		_, err := ParseLine(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	message, err := ParseArgs([]string{"game_fps=143", "--tag", "game=cs2", "--tag=map=dust2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"game_fps": 143.0}, message.Metrics)
+	assert.Equal(t, map[string]string{"game": "cs2", "map": "dust2"}, message.Tags)
+	assert.Empty(t, message.Events)
+
+	message, err = ParseArgs([]string{"--event", "Render Started", "--message", "Rendering city.blend", "--tag", "scene=city"})
+	require.NoError(t, err)
+	require.Len(t, message.Events, 1)
+	assert.Equal(t, "render_started", message.Events[0].Type)
+	assert.Equal(t, "Rendering city.blend", message.Events[0].Message)
+	assert.Equal(t, map[string]string{"scene": "city"}, message.Events[0].Details)
+
+	for _, invalid := range [][]string{{}, {"game_fps"}, {"--tag"}, {"--tag", "game"}, {"--message", "hi"}, {"--verbose", "x"}} {
+		_, err := ParseArgs(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// socketPath returns a socket path in a directory only the current user can enter
+func socketPath(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "run")
+	require.NoError(t, os.Mkdir(dir, 0700))
+	return filepath.Join(dir, "chronicle.sock")
+}
+
+func TestServer(t *testing.T) {
+	path := socketPath(t)
+	server, err := Listen(path)
+	require.NoError(t, err)
+	defer server.Close()
+
+	// Only one agent per socket
+	_, err = Listen(path)
+	assert.Error(t, err)
+
+	message, err := ParseArgs([]string{"game_fps=143", "cpu_usage=1", "--tag", "game=cs2", "--event", "match_started"})
+	require.NoError(t, err)
+	require.NoError(t, Send(path, message))
+
+	// Plain lines work too, e.g. echo game_fps=144 | nc -U chronicle.sock
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	_, err = conn.Write([]byte("game_fps=144\nbroken\n"))
+	require.NoError(t, err)
+	reply, _ := reader.ReadString('\n')
+	assert.Equal(t, "ok\n", reply)
+	reply, _ = reader.ReadString('\n')
+	assert.Equal(t, "error: \"broken\" is not key=value\n", reply)
+
+	system := models.NewSystem()
+	system.CPUUsage = "12.00%"
+	server.Apply(system)
+
+	assert.Equal(t, 144.0, system.Custom["game_fps"])
+	assert.Equal(t, "12.00%", system.ToMap()["cpu_usage"], "collected metrics win")
+	assert.Equal(t, map[string]string{"game": "cs2"}, system.Tags)
+	require.Len(t, system.Events, 1)
+	assert.Equal(t, "match_started", system.Events[0].Type)
+
+	// Everything is sent once
+	system = models.NewSystem()
+	server.Apply(system)
+	assert.Empty(t, system.Custom)
+	assert.Empty(t, system.Tags)
+	assert.Empty(t, system.Events)
+}
+
+func TestServerSocketPermissions(t *testing.T) {
+	// The directory is created private
+	path := filepath.Join(t.TempDir(), "chronicle-1000", "chronicle.sock")
+	server, err := Listen(path)
+	require.NoError(t, err)
+	defer server.Close()
+
+	info, err := os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Other users could reach the socket before its permissions are changed
+	shared := filepath.Join(t.TempDir(), "shared")
+	require.NoError(t, os.Mkdir(shared, 0755))
+	require.NoError(t, os.Chmod(shared, 0755))
+	_, err = Listen(filepath.Join(shared, "chronicle.sock"))
+	assert.ErrorContains(t, err, "can be entered by other users")
+
+	// A symlink to a private directory could be swapped by whoever created it
+	link := filepath.Join(t.TempDir(), "link")
+	require.NoError(t, os.Symlink(filepath.Dir(path), link))
+	_, err = Listen(filepath.Join(link, "chronicle.sock"))
+	assert.ErrorContains(t, err, "isn't a directory")
+
+	// A private directory of another user, only root can set that up here
+	if os.Getuid() == 0 {
+		other := filepath.Join(t.TempDir(), "other")
+		require.NoError(t, os.Mkdir(other, 0700))
+		require.NoError(t, os.Chown(other, 65534, 65534))
+		_, err = Listen(filepath.Join(other, "chronicle.sock"))
+		assert.ErrorContains(t, err, "belongs to another user")
+	}
+}
+
+func TestServerStaleSocket(t *testing.T) {
+	path := socketPath(t)
+
+	// A socket file left behind by an agent that was killed
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	server, err := Listen(path)
+	require.NoError(t, err)
+	defer server.Close()
+
+	err = Send(path, Message{Metrics: map[string]interface{}{"x": 1.0}})
+	assert.NoError(t, err)
+}
+
+func TestSendWithoutAgent(t *testing.T) {
+	err := Send(filepath.Join(t.TempDir(), "chronicle.sock"), Message{Metrics: map[string]interface{}{"x": 1.0}})
+	assert.ErrorContains(t, err, "agent not running")
+}
+
+func TestServerLimits(t *testing.T) {
+	server := &Server{metrics: make(map[string]interface{}), tags: make(map[string]string)}
+
+	for i := 0; i < MaxPendingEvents+10; i++ {
+		require.NoError(t, server.add(Message{Events: []models.Event{{Type: "tick", Timestamp: int64(i)}}}))
+	}
+	assert.Len(t, server.events, MaxPendingEvents)
+	assert.Equal(t, int64(10), server.events[0].Timestamp)
+
+	metrics := make(map[string]interface{})
+	for i := 0; i < MaxPendingMetrics; i++ {
+		metrics[time.Duration(i).String()] = 1.0
+	}
+	require.NoError(t, server.add(Message{Metrics: metrics}))
+	assert.Error(t, server.add(Message{Metrics: map[string]interface{}{"one_more": 1.0}}))
+	assert.NoError(t, server.add(Message{Metrics: map[string]interface{}{"0s": 2.0}}), "replacing a pending metric is fine")
+}
@@ -7,6 +7,9 @@ import (
 	"time"
 )
 
+// dummyBootTime stays fixed so the server doesn't see dummy clients reboot
+var dummyBootTime = time.Now().Add(-(26*time.Hour + 3*time.Minute))
+
 // randoNumber returns a random number between min and max.
 // It accepts either int or float64 as parameters.
 func randomNumber(min, max interface{}) (interface{}, error) {
@@ -51,6 +54,8 @@ func DummyData() *models.System {
 	s.UsedRAMPercentage = "50%"
 	s.Hostname = "dummy-host"
 	s.Uptime = "1d 2h 3m"
+	s.BootTime = dummyBootTime.Unix()
+	s.UptimeSeconds = uint64(time.Since(dummyBootTime).Seconds())
 	s.LoadAvg1 = "0.5"
 	s.LoadAvg5 = "0.6"
 	s.LoadAvg15 = "0.7"
@@ -66,3 +71,22 @@ func DummyData() *models.System {
 	s.SwapPercent = "50%"
 	return s
 }
+
+// DummyInventory returns a dummy host inventory for testing purposes.
+func DummyInventory() *models.Inventory {
+	return &models.Inventory{
+		Hostname:     "dummy-host",
+		OS:           "linux",
+		Distro:       "ubuntu 24.04",
+		Kernel:       "6.8.0-45-generic",
+		Architecture: "x86_64",
+		CPUModel:     "Dummy CPU @ 3.20GHz",
+		Microcode:    "0xf4",
+		CPUCores:     4,
+		CPUThreads:   8,
+		TotalRAM:     16 << 30,
+		Disks:        []models.InventoryDisk{{Name: "sda", Model: "Dummy SSD", Size: 1 << 40}},
+		NICs:         []models.InventoryNIC{{Name: "eth0", MAC: "02:00:00:00:00:01"}},
+		AgentVersion: AgentVersion(),
+	}
+}
@@ -29,3 +29,8 @@ func FormatNetworkBytes(bytes uint64) string {
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// FormatMegabytes formats bytes as MB with a fixed unit so values can be stacked in charts
+func FormatMegabytes(bytes uint64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
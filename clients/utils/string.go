@@ -1,6 +1,9 @@
 package utils
 
-import "math/rand"
+import (
+	"math/rand"
+	"strings"
+)
 
 // RandStringBytes generates a random string of n bytes
 func RandStringBytes(n int) string {
@@ -11,3 +14,18 @@ func RandStringBytes(n int) string {
 	}
 	return string(b)
 }
+
+// SanitizeMetricKey lowercases a name and replaces everything but letters, digits and
+// underscores so it matches the snake_case keys of the built-in metrics
+func SanitizeMetricKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, name)
+}
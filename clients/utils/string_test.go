@@ -0,0 +1,11 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSanitizeMetricKey(t *testing.T) {
+	assert.Equal(t, "gpu_load", SanitizeMetricKey("GPU Load"))
+	assert.Equal(t, "node_cpu_seconds", SanitizeMetricKey("node:cpu-seconds"))
+}
@@ -0,0 +1,21 @@
+package utils
+
+import "runtime/debug"
+
+// AgentVersion returns the version of the client, which Go stamps from the module
+// version or the git commit it was built from. Returns "dev" when neither is known.
+func AgentVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return setting.Value[:12]
+		}
+	}
+	return "dev"
+}
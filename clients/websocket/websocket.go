@@ -3,16 +3,26 @@ package websocket
 import (
 	"device-chronicle-client/fetch"
 	"device-chronicle-client/models"
+	"device-chronicle-client/push"
 	"device-chronicle-client/utils"
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"log"
+	"os"
+	"os/signal"
+	"reflect"
 	"strings"
+	"syscall"
 	"time"
 )
 
-func Websocket(serverAddr *string, dummy *bool, interval *int, clientName *string) {
+// inventoryInterval is how often the host inventory is checked for changes
+const inventoryInterval = time.Minute
+
+// Websocket sends a sample every interval, including anything pushed to pushServer by
+// local scripts since the previous one. pushServer may be nil.
+func Websocket(serverAddr *string, dummy *bool, interval *int, clientName *string, pushServer *push.Server) {
 	clientID := *clientName
 	log.Println("Sending data to WebSocket server with Client ID:", clientID)
 
@@ -23,11 +33,42 @@ func Websocket(serverAddr *string, dummy *bool, interval *int, clientName *strin
 	}
 	defer conn.Close()
 
+	// The inventory is sent on every connect and again whenever it changes
+	inventory := collectInventory(*dummy)
+	if err := sendInventory(conn, inventory); err != nil {
+		log.Println("Failed to send inventory:", err)
+	}
+
 	ticker := time.NewTicker(time.Duration(*interval) * time.Second)
 	defer ticker.Stop()
+	inventoryTicker := time.NewTicker(inventoryInterval)
+	defer inventoryTicker.Stop()
+
+	// systemd sends SIGTERM when the service is stopped, including on shutdown and reboot
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
 
 	for {
 		select {
+		case sig := <-stop:
+			log.Println("Received", sig, "- disconnecting from the server")
+			if err := sendGoodbye(conn); err != nil {
+				log.Println("Failed to say goodbye to the server:", err)
+			}
+			return
+
+		case <-inventoryTicker.C:
+			current := collectInventory(*dummy)
+			if reflect.DeepEqual(current, inventory) {
+				continue
+			}
+			// If this fails, the next sample fails too and the reconnect sends the inventory again
+			inventory = current
+			if err := sendInventory(conn, inventory); err != nil {
+				log.Println("Failed to send inventory:", err)
+			}
+
 		case <-ticker.C:
 
 			var systemData *models.System
@@ -43,6 +84,10 @@ func Websocket(serverAddr *string, dummy *bool, interval *int, clientName *strin
 				}
 			}
 
+			if pushServer != nil {
+				pushServer.Apply(systemData)
+			}
+
 			dataMap := systemData.ToMap()
 
 			// if there is an error sending data, close the connection and reconnect
@@ -54,6 +99,9 @@ func Websocket(serverAddr *string, dummy *bool, interval *int, clientName *strin
 					log.Println("Failed to reconnect to WebSocket server:", err)
 					return
 				}
+				if err := sendInventory(conn, inventory); err != nil {
+					log.Println("Failed to send inventory:", err)
+				}
 			}
 		}
 	}
@@ -88,6 +136,37 @@ func connectToServer(serverAddr *string, clientID string) (*websocket.Conn, erro
 	}
 }
 
+// collectInventory describes the host, nil if the OS isn't supported
+func collectInventory(dummy bool) *models.Inventory {
+	if dummy {
+		return utils.DummyInventory()
+	}
+	inventory, err := fetch.Inventory()
+	if err != nil {
+		log.Println("Error getting inventory:", err)
+		return nil
+	}
+	return inventory
+}
+
+// sendInventory sends the host inventory, doing nothing when there is none
+func sendInventory(conn *websocket.Conn, inventory *models.Inventory) error {
+	if inventory == nil {
+		return nil
+	}
+	return sendData(conn, map[string]interface{}{"type": "inventory", "inventory": inventory})
+}
+
+// sendGoodbye tells the server the client is stopping on purpose, so a reboot that
+// follows is recorded as a clean shutdown rather than a crash
+func sendGoodbye(conn *websocket.Conn) error {
+	if err := sendData(conn, map[string]interface{}{"type": "goodbye", "reason": "shutdown"}); err != nil {
+		return err
+	}
+	closing := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	return conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(time.Second))
+}
+
 func sendData(conn *websocket.Conn, data map[string]interface{}) error {
 	message, err := json.Marshal(data)
 	if err != nil {
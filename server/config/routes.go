@@ -2,14 +2,33 @@ package config
 
 import (
 	"device-chronicle-server/controllers"
+	"device-chronicle-server/logger"
+	"device-chronicle-server/models"
+	"device-chronicle-server/utils"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 func RegisterRoutes(router *gin.Engine) {
-	wsServer := controllers.NewWebSocketServer()
+	store, err := models.NewStore(utils.GetEnv("DATABASE_PATH", "storage/database/chronicle.json"))
+	if err != nil {
+		logger.Logger.Fatal("Failed to open database", zap.Error(err))
+	}
+
+	alerts := controllers.DefaultAlertConfig
+	alerts.LowBatteryPercent = utils.GetEnvFloat("LOW_BATTERY_PERCENT", alerts.LowBatteryPercent)
+	alerts.LinkFlapsPerHour = utils.GetEnvFloat("LINK_FLAPS_PER_HOUR", alerts.LinkFlapsPerHour)
+	alerts.AuthFailureBurst = utils.GetEnvFloat("AUTH_FAILURE_BURST", alerts.AuthFailureBurst)
+
+	wsServer := controllers.NewWebSocketServer(controllers.WithStore(store), controllers.WithAlertConfig(alerts))
 	router.GET("/ws", wsServer.HandleClient)
 	router.GET("/analytics/:client_id", wsServer.ServeAnalyticsPage)
 	router.GET("/analytics_ws/:client_id", wsServer.HandleAnalytics)
 	router.GET("/clients", wsServer.ListClients)
+	router.GET("/events/:client_id", wsServer.ListEvents)
+	router.GET("/energy/:client_id", wsServer.ListEnergy)
+	router.GET("/sessions/:client_id", wsServer.ListSessions)
+	router.GET("/reboots/:client_id", wsServer.ListReboots)
+	router.GET("/inventory/:client_id", wsServer.ListInventory)
 	router.GET("/", wsServer.ServeIndexPage)
 }
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// AlertConfig holds the thresholds of the alerts the server raises from client samples
+type AlertConfig struct {
+	LowBatteryPercent float64 // Alert when a battery discharges below this percentage, 0 disables
+	LinkFlapsPerHour  float64 // Alert when a network link flaps this often within an hour, 0 disables
+	AuthFailureBurst  float64 // Alert when logins fail this often within authBurstWindow, 0 disables
+}
+
+// DefaultAlertConfig is used when no alert config is provided
+var DefaultAlertConfig = AlertConfig{
+	LowBatteryPercent: 15,
+	LinkFlapsPerHour:  5,
+	AuthFailureBurst:  20,
+}
+
+// WithAlertConfig sets the alert thresholds
+func WithAlertConfig(config AlertConfig) Option {
+	return func(ws *WebSocketServer) {
+		ws.alerts = config
+	}
+}
+
+// clientState is what the server remembers about a client between samples to detect changes
+type clientState struct {
+	mu         sync.Mutex      // Held while a message of the client is processed
+	lowBattery bool            // A low battery alert was raised for the current discharge
+	session    *sessionTracker // The gaming session the client is in, if any
+	boot       *bootTracker    // Nil until the client reported its boot time
+	flaps      *flapTracker    // Nil until the client reported a link going down
+	updates    *updateStatus   // Nil until the client reported pending updates
+	auth       *authTracker    // Nil until the client reported failed logins
+}
+
+// state returns the detection state of a client, creating it on first use
+func (s *WebSocketServer) state(clientID string) *clientState {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	state, ok := s.states[clientID]
+	if !ok {
+		state = &clientState{}
+		s.states[clientID] = state
+	}
+	return state
+}
+
+// checkBattery raises an alert once per discharge when a device running on battery
+// drops below the threshold, i.e. nobody plugged it in in time
+func (s *WebSocketServer) checkBattery(clientID string, message clientMessage) []models.Event {
+	if s.alerts.LowBatteryPercent <= 0 || len(message.Batteries) == 0 {
+		return nil
+	}
+	state := s.state(clientID)
+
+	// With AC power present nobody needs to step in
+	var lowest *batteryStatus
+	if message.ACOnline == nil || !*message.ACOnline {
+		for i, battery := range message.Batteries {
+			if battery.Status == "Discharging" && (lowest == nil || battery.Capacity < lowest.Capacity) {
+				lowest = &message.Batteries[i]
+			}
+		}
+	}
+
+	if lowest == nil {
+		state.lowBattery = false
+		return nil
+	}
+	if state.lowBattery || lowest.Capacity >= s.alerts.LowBatteryPercent {
+		return nil
+	}
+	state.lowBattery = true
+
+	return []models.Event{{
+		Type:      "low_battery",
+		Timestamp: time.Now().Unix(),
+		Message:   fmt.Sprintf("Battery %s at %.0f%% and discharging without AC power", lowest.Name, lowest.Capacity),
+		Details: map[string]string{
+			"battery":   lowest.Name,
+			"capacity":  fmt.Sprintf("%.0f", lowest.Capacity),
+			"threshold": fmt.Sprintf("%.0f", s.alerts.LowBatteryPercent),
+		},
+	}}
+}
+
+// raiseEvents stores events detected by the server and pushes them to the client's analytics viewers
+func (s *WebSocketServer) raiseEvents(clientID string, events ...models.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	if err := s.store.AddEvents(clientID, events...); err != nil {
+		s.logger.Error("Failed to store events", zap.String("clientID", clientID), zap.Error(err))
+	}
+
+	msg, err := json.Marshal(map[string]interface{}{"type": "events", "events": events})
+	if err != nil {
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, analyticsConn := range s.analyticsConn[clientID] {
+		if err := analyticsConn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			s.logger.Error("Failed to send events to analytics", zap.String("clientID", clientID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	authBurstWindow  = 5 * time.Minute // Failed logins are summed over this window
+	authBurstSources = 5               // Source IPs named in a burst alert
+)
+
+// authSample is the number of failed logins a client reported in one sample
+type authSample struct {
+	time     time.Time
+	failures int
+}
+
+// authTracker sums the failed logins of a client within authBurstWindow
+type authTracker struct {
+	samples  []authSample // Oldest first
+	bursting bool         // An alert was raised and failures haven't calmed down since
+}
+
+// topSources lists the source IPs with the most failures, most first
+func topSources(sources map[string]int, limit int) []string {
+	ips := make([]string, 0, len(sources))
+	for ip := range sources {
+		ips = append(ips, ip)
+	}
+	sort.Slice(ips, func(i, j int) bool {
+		if sources[ips[i]] != sources[ips[j]] {
+			return sources[ips[i]] > sources[ips[j]]
+		}
+		return ips[i] < ips[j]
+	})
+
+	result := make([]string, 0, min(len(ips), limit))
+	for _, ip := range ips[:min(len(ips), limit)] {
+		result = append(result, fmt.Sprintf("%s (%d)", ip, sources[ip]))
+	}
+	return result
+}
+
+// checkAuthBurst sums the failed SSH and sudo logins the client reports and raises an
+// alert once AuthFailureBurst of them happen within authBurstWindow. Another alert is
+// only raised after failures calmed down below the threshold.
+func (s *WebSocketServer) checkAuthBurst(clientID string, message clientMessage) []models.Event {
+	state := s.state(clientID)
+	now := time.Now()
+
+	failures := message.AuthSSHFailed + message.AuthSudoFailed
+	if failures > 0 {
+		if state.auth == nil {
+			state.auth = &authTracker{}
+		}
+		state.auth.samples = append(state.auth.samples, authSample{time: now, failures: failures})
+	}
+	if state.auth == nil {
+		return nil
+	}
+
+	samples := state.auth.samples
+	for len(samples) > 0 && now.Sub(samples[0].time) > authBurstWindow {
+		samples = samples[1:]
+	}
+	state.auth.samples = samples
+
+	total := 0
+	for _, sample := range samples {
+		total += sample.failures
+	}
+	if s.alerts.AuthFailureBurst <= 0 || float64(total) < s.alerts.AuthFailureBurst {
+		state.auth.bursting = false
+		return nil
+	}
+	if state.auth.bursting {
+		return nil
+	}
+	state.auth.bursting = true
+
+	sources := topSources(message.AuthSources, authBurstSources)
+	text := fmt.Sprintf("%d failed logins within the last %.0f minutes", total, authBurstWindow.Minutes())
+	if len(sources) > 0 {
+		text += ", mostly from " + strings.Join(sources, ", ")
+	}
+	return []models.Event{{
+		Type:      "auth_burst",
+		Timestamp: now.Unix(),
+		Message:   text,
+		Details: map[string]string{
+			"failures":  strconv.Itoa(total),
+			"threshold": fmt.Sprintf("%.0f", s.alerts.AuthFailureBurst),
+			"sources":   strings.Join(sources, ","),
+		},
+	}}
+}
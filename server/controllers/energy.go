@@ -0,0 +1,12 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ListEnergy API to list the daily energy usage of a client in kWh
+func (s *WebSocketServer) ListEnergy(c *gin.Context) {
+	clientID := c.Param("client_id")
+	c.JSON(http.StatusOK, gin.H{"energy": s.store.Energy(clientID)})
+}
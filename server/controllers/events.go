@@ -0,0 +1,12 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ListEvents API to list the stored events of a client
+func (s *WebSocketServer) ListEvents(c *gin.Context) {
+	clientID := c.Param("client_id")
+	c.JSON(http.StatusOK, gin.H{"events": s.store.Events(clientID)})
+}
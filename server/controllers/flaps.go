@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// flapWindow is the period over which link flaps are counted
+const flapWindow = time.Hour
+
+// flapTracker counts the flaps of a client's network links
+type flapTracker struct {
+	times    map[string][]time.Time // Interface -> when it flapped within flapWindow, oldest first
+	flapping map[string]bool        // An alert was raised and the link hasn't calmed down since
+}
+
+// checkFlapping counts every link going down the client reports, or flapping in between
+// samples, except interfaces being removed, and raises an alert once a link flaps LinkFlapsPerHour times within an hour.
+// Another alert is only raised after the link calmed down below the threshold.
+func (s *WebSocketServer) checkFlapping(clientID string, message clientMessage) []models.Event {
+	state := s.state(clientID)
+	now := time.Now()
+
+	for _, event := range message.Events {
+		flaps := 0
+		switch event.Type {
+		case "link_down":
+			// A removed interface didn't lose its link, e.g. a container or VPN was stopped
+			if event.Details["removed"] != "true" {
+				flaps = 1
+			}
+		case "link_flap":
+			flaps, _ = strconv.Atoi(event.Details["flaps"])
+		}
+		if flaps <= 0 {
+			continue
+		}
+
+		if state.flaps == nil {
+			state.flaps = &flapTracker{times: make(map[string][]time.Time), flapping: make(map[string]bool)}
+		}
+		iface := event.Details["interface"]
+		for i := 0; i < flaps; i++ {
+			state.flaps.times[iface] = append(state.flaps.times[iface], now)
+		}
+	}
+	if state.flaps == nil {
+		return nil
+	}
+
+	ifaces := make([]string, 0, len(state.flaps.times))
+	for iface := range state.flaps.times {
+		ifaces = append(ifaces, iface)
+	}
+	sort.Strings(ifaces)
+
+	var events []models.Event
+	for _, iface := range ifaces {
+		times := state.flaps.times[iface]
+		for len(times) > 0 && now.Sub(times[0]) > flapWindow {
+			times = times[1:]
+		}
+		if len(times) == 0 {
+			delete(state.flaps.times, iface)
+			delete(state.flaps.flapping, iface)
+			continue
+		}
+		state.flaps.times[iface] = times
+
+		if s.alerts.LinkFlapsPerHour <= 0 || float64(len(times)) < s.alerts.LinkFlapsPerHour {
+			state.flaps.flapping[iface] = false
+			continue
+		}
+		if state.flaps.flapping[iface] {
+			continue
+		}
+		state.flaps.flapping[iface] = true
+
+		events = append(events, models.Event{
+			Type:      "link_flapping",
+			Timestamp: now.Unix(),
+			Message:   fmt.Sprintf("%s flapped %d times within the last hour", iface, len(times)),
+			Details: map[string]string{
+				"interface": iface,
+				"flaps":     strconv.Itoa(len(times)),
+				"threshold": fmt.Sprintf("%.0f", s.alerts.LinkFlapsPerHour),
+			},
+		})
+	}
+	return events
+}
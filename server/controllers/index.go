@@ -3,8 +3,15 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"sort"
 )
 
+// clientSummary is a client as listed on the index page
+type clientSummary struct {
+	ID      string
+	Updates *updateStatus // Nil if the client never reported its updates
+}
+
 // ServeIndexPage serves the main index page with client list
 func (s *WebSocketServer) ServeIndexPage(c *gin.Context) {
 	// Get list of all connected clients
@@ -14,8 +21,17 @@ func (s *WebSocketServer) ServeIndexPage(c *gin.Context) {
 		clientIDs = append(clientIDs, client)
 	}
 	s.mu.RUnlock()
+	sort.Strings(clientIDs)
+
+	clients := make([]clientSummary, len(clientIDs))
+	for i, clientID := range clientIDs {
+		state := s.state(clientID)
+		state.mu.Lock()
+		clients[i] = clientSummary{ID: clientID, Updates: state.updates}
+		state.mu.Unlock()
+	}
 
 	c.HTML(http.StatusOK, "index.html", gin.H{
-		"clients": clientIDs,
+		"clients": clients,
 	})
 }
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"time"
+)
+
+// ramChangeTolerance is the share by which the total RAM may vary between inventories without
+// being reported. The kernel reserves memory depending on the firmware and its own version.
+const ramChangeTolerance = 0.02
+
+// formatGiB formats a size in bytes as GiB
+func formatGiB(bytes uint64) string {
+	return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+}
+
+// diskKey identifies a disk by its serial, which unlike its name survives reordering
+func diskKey(disk models.InventoryDisk) string {
+	if disk.Serial != "" {
+		return disk.Serial
+	}
+	return disk.Name
+}
+
+// nicKey identifies a network interface by its MAC address, which survives renames
+func nicKey(nic models.InventoryNIC) string {
+	if nic.MAC != "" {
+		return nic.MAC
+	}
+	return nic.Name
+}
+
+// ramChanged reports whether the total RAM changed by more than ramChangeTolerance
+func ramChanged(previous, current uint64) bool {
+	difference := math.Abs(float64(current) - float64(previous))
+	return difference > ramChangeTolerance*float64(max(previous, current))
+}
+
+// diffInventory returns an event for every hardware or configuration change between two inventories
+func diffInventory(previous, current models.Inventory) []models.Event {
+	now := time.Now().Unix()
+	var events []models.Event
+	change := func(eventType, message string, details map[string]string) {
+		events = append(events, models.Event{Type: eventType, Timestamp: now, Message: message, Details: details})
+	}
+
+	if ramChanged(previous.TotalRAM, current.TotalRAM) {
+		change("ram_changed",
+			fmt.Sprintf("Memory changed from %s to %s", formatGiB(previous.TotalRAM), formatGiB(current.TotalRAM)),
+			map[string]string{"from": fmt.Sprint(previous.TotalRAM), "to": fmt.Sprint(current.TotalRAM)})
+	}
+	if previous.Kernel != current.Kernel {
+		change("kernel_changed",
+			fmt.Sprintf("Kernel changed from %s to %s", previous.Kernel, current.Kernel),
+			map[string]string{"from": previous.Kernel, "to": current.Kernel})
+	}
+	// Older clients don't report the microcode
+	if previous.Microcode != "" && current.Microcode != "" && previous.Microcode != current.Microcode {
+		change("microcode_changed",
+			fmt.Sprintf("CPU microcode changed from %s to %s", previous.Microcode, current.Microcode),
+			map[string]string{"from": previous.Microcode, "to": current.Microcode})
+	}
+
+	previousDisks := make(map[string]bool, len(previous.Disks))
+	for _, disk := range previous.Disks {
+		previousDisks[diskKey(disk)] = true
+	}
+	currentDisks := make(map[string]bool, len(current.Disks))
+	for _, disk := range current.Disks {
+		currentDisks[diskKey(disk)] = true
+		if !previousDisks[diskKey(disk)] {
+			change("disk_added",
+				fmt.Sprintf("Disk %s added: %s (%s)", disk.Name, disk.Model, formatGiB(disk.Size)),
+				map[string]string{"disk": disk.Name, "model": disk.Model, "serial": disk.Serial})
+		}
+	}
+	for _, disk := range previous.Disks {
+		if !currentDisks[diskKey(disk)] {
+			change("disk_removed",
+				fmt.Sprintf("Disk %s removed: %s (%s)", disk.Name, disk.Model, formatGiB(disk.Size)),
+				map[string]string{"disk": disk.Name, "model": disk.Model, "serial": disk.Serial})
+		}
+	}
+
+	currentNICs := make(map[string]bool, len(current.NICs))
+	for _, nic := range current.NICs {
+		currentNICs[nicKey(nic)] = true
+	}
+	for _, nic := range previous.NICs {
+		if !currentNICs[nicKey(nic)] {
+			change("nic_removed",
+				fmt.Sprintf("Network interface %s (%s) disappeared", nic.Name, nic.MAC),
+				map[string]string{"interface": nic.Name, "mac": nic.MAC})
+		}
+	}
+	return events
+}
+
+// handleInventory records the inventory a client sends on connect and whenever it changes,
+// raising an event for every change to the previous one
+func (s *WebSocketServer) handleInventory(clientID string, inventory *models.Inventory) {
+	if inventory == nil {
+		s.logger.Warn("Inventory message without inventory", zap.String("clientID", clientID))
+		return
+	}
+
+	snapshot := models.InventorySnapshot{Timestamp: time.Now().Unix(), Inventory: *inventory}
+	previous, added, err := s.store.AddInventory(clientID, snapshot)
+	if err != nil {
+		s.logger.Error("Failed to store inventory", zap.String("clientID", clientID), zap.Error(err))
+	}
+	if added {
+		s.logger.Info("Inventory changed", zap.String("clientID", clientID))
+	}
+	if previous != nil {
+		s.raiseEvents(clientID, diffInventory(*previous, *inventory)...)
+	}
+}
+
+// ListInventory API to get the latest inventory of a client and its change history
+func (s *WebSocketServer) ListInventory(c *gin.Context) {
+	clientID := c.Param("client_id")
+	history := s.store.Inventories(clientID)
+
+	var latest *models.Inventory
+	if len(history) > 0 {
+		latest = &history[len(history)-1].Inventory
+	}
+	c.JSON(http.StatusOK, gin.H{"inventory": latest, "history": history})
+}
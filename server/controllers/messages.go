@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"encoding/json"
+	"go.uber.org/zap"
+	"time"
+)
+
+// clientMessage holds the parts of a client sample the server acts on,
+// everything else is only forwarded to analytics viewers
+type clientMessage struct {
+	Type          string            `json:"type"` // Set on control frames only, e.g. "goodbye"
+	Inventory     *models.Inventory `json:"inventory"`
+	BootTime      int64             `json:"boot_time"`
+	UptimeSeconds int64             `json:"uptime_seconds"`
+	Events        []models.Event    `json:"events"`
+	EnergyJoules  float64           `json:"energy_joules"` // Energy used since the previous sample
+	ACOnline      *bool             `json:"ac_online"`
+	Batteries     []batteryStatus   `json:"batteries"`
+
+	// Pending OS updates, only reported once the client checked
+	UpdatesPending  *int  `json:"updates_pending"`
+	UpdatesSecurity *int  `json:"updates_security"`
+	RebootRequired  *bool `json:"reboot_required"`
+
+	// Failed logins since the previous sample, only reported when the client collects them
+	AuthSSHFailed  int            `json:"auth_ssh_failed"`
+	AuthSudoFailed int            `json:"auth_sudo_failed"`
+	AuthSources    map[string]int `json:"auth_failed_sources"` // Source IP -> failures within the last hour
+
+	// Gaming sessions
+	Tags     map[string]string `json:"tags"`
+	CPUTemp  metric            `json:"cpu_temp"`
+	CPUUsage metric            `json:"cpu_usage"`
+	RAMUsage metric            `json:"used_ram_percentage"`
+	FPS      metric            `json:"game_fps"`
+}
+
+// batteryStatus is the part of a client battery report used for alerts
+type batteryStatus struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Capacity float64 `json:"capacity"`
+}
+
+// processMessage stores anything worth keeping from a client message. Returns whether
+// the message should be forwarded to analytics viewers, which goodbye frames aren't.
+func (s *WebSocketServer) processMessage(clientID string, msg []byte) bool {
+	var message clientMessage
+	if err := json.Unmarshal(msg, &message); err != nil {
+		s.logger.Warn("Invalid message from client", zap.String("clientID", clientID), zap.Error(err))
+		return true
+	}
+
+	// Detection state is per client, a client may have more than one connection
+	state := s.state(clientID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	switch message.Type {
+	case "goodbye":
+		s.handleGoodbye(clientID)
+		return false
+	case "inventory":
+		s.handleInventory(clientID, message.Inventory)
+		return true
+	}
+
+	if err := s.store.AddEvents(clientID, message.Events...); err != nil {
+		s.logger.Error("Failed to store events", zap.String("clientID", clientID), zap.Error(err))
+	}
+	if err := s.store.AddEnergy(clientID, time.Now(), message.EnergyJoules); err != nil {
+		s.logger.Error("Failed to store energy", zap.String("clientID", clientID), zap.Error(err))
+	}
+
+	s.raiseEvents(clientID, s.checkReboot(clientID, message)...)
+	s.raiseEvents(clientID, s.checkBattery(clientID, message)...)
+	s.raiseEvents(clientID, s.checkFlapping(clientID, message)...)
+	s.raiseEvents(clientID, s.checkAuthBurst(clientID, message)...)
+	s.trackUpdates(clientID, message)
+	s.trackSession(clientID, message)
+	return true
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// bootTimeTolerance absorbs clock adjustments, which shift the boot time a client derives from its uptime
+const bootTimeTolerance = 60 // seconds
+
+// bootTracker is what the server last saw of a client's boot
+type bootTracker struct {
+	bootTime int64
+	uptime   int64
+	lastSeen time.Time
+	goodbye  bool // The client said goodbye after its last sample
+}
+
+// saveBoot stores the boot the server last saw of a client, so a reboot while the server is down
+// is still detected from the first sample after it restarts
+func (s *WebSocketServer) saveBoot(clientID string, boot *bootTracker) {
+	stored := models.Boot{BootTime: boot.bootTime, Uptime: boot.uptime, LastSeen: boot.lastSeen.Unix(), Goodbye: boot.goodbye}
+	if err := s.store.SetBoot(clientID, stored); err != nil {
+		s.logger.Error("Failed to store boot", zap.String("clientID", clientID), zap.Error(err))
+	}
+}
+
+// formatUptime formats seconds of uptime the way clients report it, e.g. "1d 2h 3m"
+func formatUptime(seconds int64) string {
+	return fmt.Sprintf("%dd %dh %dm", seconds/86400, seconds%86400/3600, seconds%3600/60)
+}
+
+// checkReboot detects a reboot from the boot time moving forward or the uptime going backwards.
+// The reboot was clean if the client said goodbye before going away, otherwise it crashed or lost power.
+func (s *WebSocketServer) checkReboot(clientID string, message clientMessage) []models.Event {
+	if message.BootTime == 0 {
+		return nil
+	}
+	state := s.state(clientID)
+	previous := state.boot
+	if previous == nil {
+		// The first sample since the server started, compare with the boot stored before
+		if boot, ok := s.store.Boot(clientID); ok {
+			previous = &bootTracker{bootTime: boot.BootTime, uptime: boot.Uptime, lastSeen: time.Unix(boot.LastSeen, 0), goodbye: boot.Goodbye}
+		}
+	}
+	state.boot = &bootTracker{bootTime: message.BootTime, uptime: message.UptimeSeconds, lastSeen: time.Now()}
+	s.saveBoot(clientID, state.boot)
+
+	if previous == nil {
+		return nil
+	}
+	if message.BootTime <= previous.bootTime+bootTimeTolerance && message.UptimeSeconds >= previous.uptime {
+		return nil
+	}
+
+	reboot := models.Reboot{
+		BootTime:         message.BootTime,
+		PreviousBootTime: previous.bootTime,
+		PreviousUptime:   previous.uptime,
+		LastSeen:         previous.lastSeen.Unix(),
+		Clean:            previous.goodbye,
+	}
+	if err := s.store.AddReboot(clientID, reboot); err != nil {
+		s.logger.Error("Failed to store reboot", zap.String("clientID", clientID), zap.Error(err))
+	}
+
+	description := fmt.Sprintf("Rebooted after %s of uptime", formatUptime(reboot.PreviousUptime))
+	if reboot.Clean {
+		description += ", clean shutdown"
+	} else {
+		description += " without a clean shutdown, crashed or lost power"
+	}
+	return []models.Event{{
+		Type:      "reboot",
+		Timestamp: reboot.BootTime,
+		Message:   description,
+		Details: map[string]string{
+			"boot_time":          strconv.FormatInt(reboot.BootTime, 10),
+			"previous_boot_time": strconv.FormatInt(reboot.PreviousBootTime, 10),
+			"previous_uptime":    strconv.FormatInt(reboot.PreviousUptime, 10),
+			"clean":              strconv.FormatBool(reboot.Clean),
+		},
+	}}
+}
+
+// handleGoodbye remembers that a client disconnected on purpose and stores its open session
+func (s *WebSocketServer) handleGoodbye(clientID string) {
+	state := s.state(clientID)
+	if state.boot != nil {
+		state.boot.goodbye = true
+		s.saveBoot(clientID, state.boot)
+	}
+	s.endSession(clientID, state)
+	s.logger.Info("Client said goodbye", zap.String("clientID", clientID))
+}
+
+// ListReboots API to list the reboot history of a client
+func (s *WebSocketServer) ListReboots(c *gin.Context) {
+	clientID := c.Param("client_id")
+	c.JSON(http.StatusOK, gin.H{"reboots": s.store.Reboots(clientID)})
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"device-chronicle-server/models"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// metric is a sample value sent either as a number or as a string with a unit, e.g. "65.00°C".
+// Values that are neither are ignored rather than failing the whole message.
+type metric struct {
+	value float64
+	ok    bool
+}
+
+func (m *metric) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.value); err == nil {
+		m.ok = true
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return nil
+	}
+	_, err := fmt.Sscanf(text, "%g", &m.value)
+	m.ok = err == nil
+	return nil
+}
+
+// sessionTimeout is how long a session stays open without a sample tagged with it
+const sessionTimeout = 5 * time.Minute
+
+// sessionTracker accumulates the samples of a running gaming session
+type sessionTracker struct {
+	id      string
+	game    string
+	start   time.Time
+	last    time.Time
+	samples int
+	values  map[string][]float64
+}
+
+// finish summarizes the samples into a session
+func (t *sessionTracker) finish() models.Session {
+	stats := make(map[string]models.SessionStat, len(t.values))
+	for name, values := range t.values {
+		stats[name] = models.NewSessionStat(values)
+	}
+	return models.Session{
+		ID:      t.id,
+		Game:    t.game,
+		Start:   t.start.Unix(),
+		End:     t.last.Unix(),
+		Samples: t.samples,
+		Stats:   stats,
+	}
+}
+
+// endSession stores the open session of a client, if any. The caller holds the client's state lock.
+func (s *WebSocketServer) endSession(clientID string, state *clientState) {
+	if state.session == nil {
+		return
+	}
+	if err := s.store.AddSession(clientID, state.session.finish()); err != nil {
+		s.logger.Error("Failed to store session", zap.String("clientID", clientID), zap.Error(err))
+	}
+	state.session = nil
+}
+
+// trackSession follows the gaming session a client tags its samples with. A session ends
+// with the client's game_stopped event, the first sample that has no or another session
+// tag, or after sessionTimeout without samples.
+func (s *WebSocketServer) trackSession(clientID string, message clientMessage) {
+	state := s.state(clientID)
+	sessionID := message.Tags["session"]
+	now := time.Now()
+
+	if state.session != nil && now.Sub(state.session.last) > sessionTimeout {
+		s.endSession(clientID, state)
+	}
+	for _, event := range message.Events {
+		if event.Type != "game_stopped" || event.Details["session"] == "" {
+			continue
+		}
+		if state.session != nil && event.Details["session"] == state.session.id {
+			s.endSession(clientID, state)
+		}
+		// The sample reporting the stop doesn't start the session again
+		if event.Details["session"] == sessionID {
+			sessionID = ""
+		}
+	}
+	if state.session != nil && state.session.id != sessionID {
+		s.endSession(clientID, state)
+	}
+	if sessionID == "" {
+		return
+	}
+
+	if state.session == nil {
+		state.session = &sessionTracker{
+			id:     sessionID,
+			game:   message.Tags["game"],
+			start:  now,
+			values: make(map[string][]float64),
+		}
+	}
+	tracker := state.session
+	tracker.last = now
+	tracker.samples++
+
+	for name, value := range map[string]metric{
+		"cpu_temp":  message.CPUTemp,
+		"cpu_usage": message.CPUUsage,
+		"ram_usage": message.RAMUsage,
+		"fps":       message.FPS,
+	} {
+		if value.ok {
+			tracker.values[name] = append(tracker.values[name], value.value)
+		}
+	}
+}
+
+// expireSessions periodically stores the sessions of clients that stopped sending samples
+// without closing their connection
+func (s *WebSocketServer) expireSessions(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.stateMu.Lock()
+		clientIDs := make([]string, 0, len(s.states))
+		for clientID := range s.states {
+			clientIDs = append(clientIDs, clientID)
+		}
+		s.stateMu.Unlock()
+
+		for _, clientID := range clientIDs {
+			state := s.state(clientID)
+			state.mu.Lock()
+			if state.session != nil && time.Since(state.session.last) > sessionTimeout {
+				s.endSession(clientID, state)
+			}
+			state.mu.Unlock()
+		}
+	}
+}
+
+// ListSessions API to list the gaming sessions of a client
+func (s *WebSocketServer) ListSessions(c *gin.Context) {
+	clientID := c.Param("client_id")
+	c.JSON(http.StatusOK, gin.H{"sessions": s.store.Sessions(clientID)})
+}
@@ -0,0 +1,31 @@
+package controllers
+
+// updateStatus is the latest pending OS updates report of a client
+type updateStatus struct {
+	Pending        int
+	Security       int
+	RebootRequired bool
+}
+
+// NeedsPatching reports whether the client has updates to install or has to reboot to apply them
+func (u *updateStatus) NeedsPatching() bool {
+	return u != nil && (u.Pending > 0 || u.Security > 0 || u.RebootRequired)
+}
+
+// trackUpdates keeps the latest pending updates report of a client
+func (s *WebSocketServer) trackUpdates(clientID string, message clientMessage) {
+	if message.UpdatesPending == nil && message.UpdatesSecurity == nil && message.RebootRequired == nil {
+		return
+	}
+	status := &updateStatus{}
+	if message.UpdatesPending != nil {
+		status.Pending = *message.UpdatesPending
+	}
+	if message.UpdatesSecurity != nil {
+		status.Security = *message.UpdatesSecurity
+	}
+	if message.RebootRequired != nil {
+		status.RebootRequired = *message.RebootRequired
+	}
+	s.state(clientID).updates = status
+}
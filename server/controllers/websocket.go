@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"device-chronicle-server/models"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
@@ -24,6 +25,10 @@ type WebSocketServer struct {
 	analyticsConn map[string][]*websocket.Conn
 	mu            sync.RWMutex // Add mutex for thread safety
 	logger        *zap.Logger
+	store         *models.Store
+	alerts        AlertConfig
+	states        map[string]*clientState
+	stateMu       sync.Mutex
 }
 
 func WithLogger(logger *zap.Logger) Option {
@@ -32,10 +37,19 @@ func WithLogger(logger *zap.Logger) Option {
 	}
 }
 
+// WithStore sets where client history such as events is kept
+func WithStore(store *models.Store) Option {
+	return func(ws *WebSocketServer) {
+		ws.store = store
+	}
+}
+
 func NewWebSocketServer(opts ...Option) *WebSocketServer {
 	ws := &WebSocketServer{
 		clients:       make(map[string][]*websocket.Conn),
 		analyticsConn: make(map[string][]*websocket.Conn),
+		alerts:        DefaultAlertConfig,
+		states:        make(map[string]*clientState),
 	}
 
 	// Apply options
@@ -48,6 +62,13 @@ func NewWebSocketServer(opts ...Option) *WebSocketServer {
 		ws.logger, _ = zap.NewProduction()
 	}
 
+	// Keep history in memory only if no store provided
+	if ws.store == nil {
+		ws.store, _ = models.NewStore("")
+	}
+
+	go ws.expireSessions(time.Minute)
+
 	return ws
 }
 
@@ -83,6 +104,10 @@ func (s *WebSocketServer) HandleClient(c *gin.Context) {
 		}
 		//s.logger.Info("Received from client", zap.String("clientID", clientID), zap.String("message", string(msg)))
 
+		if !s.processMessage(clientID, msg) {
+			continue
+		}
+
 		// Forward message to analytics WebSocket if connected
 		s.mu.RLock()
 		if analyticsConns, ok := s.analyticsConn[clientID]; ok {
@@ -110,6 +135,14 @@ func (s *WebSocketServer) HandleClient(c *gin.Context) {
 		s.clients[clientID] = connections // Update the connections list
 	}
 	s.mu.Unlock()
+
+	// A session can't go on without a connection, store it rather than lose it
+	if len(connections) == 0 {
+		state := s.state(clientID)
+		state.mu.Lock()
+		s.endSession(clientID, state)
+		state.mu.Unlock()
+	}
 }
 
 // ServeAnalyticsPage Serve analytics HTML page
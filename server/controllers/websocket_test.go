@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"device-chronicle-server/models"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
@@ -8,6 +10,7 @@ import (
 	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -222,3 +225,501 @@ func TestListClients(t *testing.T) {
 		})
 	}
 }
+
+func TestListEvents(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/events/:client_id", ts.wsServer.ListEvents)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "test-client")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	sample := `{"cpu_usage":"12.00%","events":[{"type":"oom_kill","timestamp":1700000000,"message":"OOM killer terminated steam (pid 4321)","details":{"process":"steam","pid":"4321"}}]}`
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(sample)))
+	time.Sleep(50 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/events/test-client", nil)
+	ts.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"events":[{"type":"oom_kill","timestamp":1700000000,"message":"OOM killer terminated steam (pid 4321)","details":{"process":"steam","pid":"4321"}}]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/events/unknown-client", nil)
+	ts.router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"events":[]}`, w.Body.String())
+}
+
+func TestListEnergy(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/energy/:client_id", ts.wsServer.ListEnergy)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "test-client")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// Two samples of 0.5 kWh each
+	for i := 0; i < 2; i++ {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"power_total":"45.00 W","energy_joules":1800000}`)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/energy/test-client", nil)
+	ts.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	today := time.Now().Format(time.DateOnly)
+	assert.JSONEq(t, `{"energy":[{"date":"`+today+`","kwh":1}]}`, w.Body.String())
+}
+
+func TestLowBatteryAlert(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/analytics_ws/:client_id", ts.wsServer.HandleAnalytics)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "laptop")
+	require.NoError(t, err)
+	defer ws.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	analyticsURL := "ws" + strings.TrimPrefix(ts.server.URL, "http") + "/analytics_ws/laptop"
+	analytics, _, err := websocket.DefaultDialer.Dial(analyticsURL, nil)
+	require.NoError(t, err)
+	defer analytics.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	samples := []string{
+		`{"ac_online":false,"batteries":[{"name":"BAT0","status":"Discharging","capacity":20}]}`,
+		`{"ac_online":false,"batteries":[{"name":"BAT0","status":"Discharging","capacity":14}]}`,
+		// Only one alert per discharge
+		`{"ac_online":false,"batteries":[{"name":"BAT0","status":"Discharging","capacity":13}]}`,
+		// Plugged in and unplugged again
+		`{"ac_online":true,"batteries":[{"name":"BAT0","status":"Charging","capacity":14}]}`,
+		`{"ac_online":false,"batteries":[{"name":"BAT0","status":"Discharging","capacity":14}]}`,
+	}
+	for _, sample := range samples {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(sample)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	events := ts.wsServer.store.Events("laptop")
+	require.Len(t, events, 2)
+	assert.Equal(t, "low_battery", events[0].Type)
+	assert.Equal(t, "Battery BAT0 at 14% and discharging without AC power", events[0].Message)
+	assert.Equal(t, "15", events[0].Details["threshold"])
+
+	// Viewers get the forwarded samples and the alerts raised by the server
+	alerts := 0
+	analytics.SetReadDeadline(time.Now().Add(time.Second))
+	for i := 0; i < len(samples)+2; i++ {
+		_, msg, err := analytics.ReadMessage()
+		require.NoError(t, err)
+		if strings.Contains(string(msg), `"type":"events"`) {
+			alerts++
+		}
+	}
+	assert.Equal(t, 2, alerts)
+}
+
+func TestListSessions(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/sessions/:client_id", ts.wsServer.ListSessions)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "desktop")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	samples := []string{
+		`{"cpu_temp":"60.00°C","cpu_usage":"10.00%"}`,
+		`{"cpu_temp":"70.00°C","cpu_usage":"50.00%","used_ram_percentage":"40.00%","game_fps":"140.0 fps","tags":{"session":"1700000000-cs2","game":"cs2"}}`,
+		`{"cpu_temp":"80.00°C","cpu_usage":"70.00%","used_ram_percentage":"42.00%","game_fps":160,"tags":{"session":"1700000000-cs2","game":"cs2"}}`,
+		// The game exited
+		`{"cpu_temp":"65.00°C","cpu_usage":"12.00%"}`,
+	}
+	for _, sample := range samples {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(sample)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/sessions/desktop", nil)
+	ts.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Sessions []models.Session `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Sessions, 1)
+
+	session := body.Sessions[0]
+	assert.Equal(t, "1700000000-cs2", session.ID)
+	assert.Equal(t, "cs2", session.Game)
+	assert.Equal(t, 2, session.Samples)
+	assert.Equal(t, models.SessionStat{Min: 70, Avg: 75, Max: 80, P95: 80}, session.Stats["cpu_temp"])
+	assert.Equal(t, models.SessionStat{Min: 140, Avg: 150, Max: 160, P95: 160}, session.Stats["fps"])
+	assert.Equal(t, 41.0, session.Stats["ram_usage"].Avg)
+}
+
+func TestRebootWhileServerDown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.json")
+	start := func() *testSetup {
+		store, err := models.NewStore(path)
+		require.NoError(t, err)
+		logger, _ := zap.NewDevelopment()
+		ts := &testSetup{wsServer: NewWebSocketServer(WithLogger(logger), WithStore(store)), router: gin.New()}
+		ts.server = httptest.NewServer(ts.router)
+		ts.router.GET("/ws", ts.wsServer.HandleClient)
+		return ts
+	}
+	send := func(ts *testSetup, messages ...string) {
+		ws, _, err := setupTestClient(ts, "desktop")
+		require.NoError(t, err)
+		defer ws.Close()
+		for _, message := range messages {
+			require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	ts := start()
+	send(ts, `{"boot_time":1700000000,"uptime_seconds":90000}`, `{"type":"goodbye","reason":"shutdown"}`)
+	ts.server.Close()
+
+	// The client rebooted while the server was down
+	ts = start()
+	defer ts.server.Close()
+	send(ts, `{"boot_time":1700100000,"uptime_seconds":60}`)
+
+	reboots := ts.wsServer.store.Reboots("desktop")
+	require.Len(t, reboots, 1)
+	assert.Equal(t, models.Reboot{
+		BootTime:         1700100000,
+		PreviousBootTime: 1700000000,
+		PreviousUptime:   90000,
+		LastSeen:         reboots[0].LastSeen,
+		Clean:            true,
+	}, reboots[0])
+	assert.NotZero(t, reboots[0].LastSeen)
+
+	// Samples of the same boot after another restart aren't a reboot
+	ts.server.Close()
+	ts = start()
+	defer ts.server.Close()
+	send(ts, `{"boot_time":1700100000,"uptime_seconds":120}`)
+	assert.Len(t, ts.wsServer.store.Reboots("desktop"), 1)
+}
+
+func TestSessionEnds(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	defer ts.server.Close()
+
+	sessionIDs := func() []string {
+		var ids []string
+		for _, session := range ts.wsServer.store.Sessions("desktop") {
+			ids = append(ids, session.ID)
+		}
+		return ids
+	}
+	send := func(ws *websocket.Conn, messages ...string) {
+		for _, message := range messages {
+			require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	ws, _, err := setupTestClient(ts, "desktop")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// The client's game_stopped event ends the session, even on a sample still tagged with it
+	send(ws, `{"tags":{"session":"1-cs2","game":"cs2"}}`,
+		`{"tags":{"session":"1-cs2","game":"cs2"},"events":[{"type":"game_stopped","details":{"session":"1-cs2"}}]}`)
+	assert.Equal(t, []string{"1-cs2"}, sessionIDs())
+
+	// A goodbye mid-game stores the session
+	send(ws, `{"tags":{"session":"2-dota2","game":"dota2"}}`, `{"type":"goodbye","reason":"shutdown"}`)
+	assert.Equal(t, []string{"1-cs2", "2-dota2"}, sessionIDs())
+
+	// So does the connection dropping
+	send(ws, `{"tags":{"session":"3-factorio","game":"factorio"}}`)
+	require.NoError(t, ws.Close())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{"1-cs2", "2-dota2", "3-factorio"}, sessionIDs())
+
+	// A session without samples for too long is stored before the next one starts
+	ws, _, err = setupTestClient(ts, "desktop")
+	require.NoError(t, err)
+	defer ws.Close()
+	send(ws, `{"tags":{"session":"4-cs2","game":"cs2"}}`)
+	state := ts.wsServer.state("desktop")
+	state.mu.Lock()
+	state.session.last = time.Now().Add(-sessionTimeout - time.Minute)
+	state.mu.Unlock()
+	send(ws, `{"tags":{"session":"4-cs2","game":"cs2"}}`)
+	assert.Equal(t, []string{"1-cs2", "2-dota2", "3-factorio", "4-cs2"}, sessionIDs())
+}
+
+func TestListReboots(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/reboots/:client_id", ts.wsServer.ListReboots)
+	ts.router.GET("/events/:client_id", ts.wsServer.ListEvents)
+	defer ts.server.Close()
+
+	send := func(messages ...string) {
+		ws, _, err := setupTestClient(ts, "desktop")
+		require.NoError(t, err)
+		defer ws.Close()
+		for _, message := range messages {
+			require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(message)))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// A clean reboot, the client said goodbye before going down
+	send(`{"boot_time":1700000000,"uptime_seconds":90000}`, `{"boot_time":1700000001,"uptime_seconds":90060}`, `{"type":"goodbye","reason":"shutdown"}`)
+	send(`{"boot_time":1700090200,"uptime_seconds":30}`)
+	// A crash, the uptime went backwards without a goodbye
+	send(`{"boot_time":1700090200,"uptime_seconds":3690}`, `{"boot_time":1700093900,"uptime_seconds":20}`)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/reboots/desktop", nil)
+	ts.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Reboots []models.Reboot `json:"reboots"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Reboots, 2)
+	assert.Equal(t, int64(1700090200), body.Reboots[0].BootTime)
+	assert.Equal(t, int64(1700000001), body.Reboots[0].PreviousBootTime)
+	assert.Equal(t, int64(90060), body.Reboots[0].PreviousUptime)
+	assert.True(t, body.Reboots[0].Clean)
+	assert.Equal(t, int64(3690), body.Reboots[1].PreviousUptime)
+	assert.False(t, body.Reboots[1].Clean)
+
+	events := ts.wsServer.store.Events("desktop")
+	require.Len(t, events, 2)
+	assert.Equal(t, "reboot", events[0].Type)
+	assert.Equal(t, "Rebooted after 1d 1h 1m of uptime, clean shutdown", events[0].Message)
+	assert.Equal(t, int64(1700090200), events[0].Timestamp)
+	assert.Equal(t, "false", events[1].Details["clean"])
+}
+
+func TestListInventory(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/inventory/:client_id", ts.wsServer.ListInventory)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "desktop")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// Sent on connect, again after a reconnect and after a kernel upgrade
+	messages := []string{
+		`{"type":"inventory","inventory":{"hostname":"desktop","kernel":"6.8.0-45-generic","total_ram":34359738368,"disks":[{"name":"nvme0n1","size":1000204886016}]}}`,
+		`{"type":"inventory","inventory":{"hostname":"desktop","kernel":"6.8.0-45-generic","total_ram":34359738368,"disks":[{"name":"nvme0n1","size":1000204886016}]}}`,
+		`{"type":"inventory","inventory":{"hostname":"desktop","kernel":"6.8.0-47-generic","total_ram":34359738368,"disks":[{"name":"nvme0n1","size":1000204886016}]}}`,
+	}
+	for _, message := range messages {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(message)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/inventory/desktop", nil)
+	ts.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Inventory *models.Inventory          `json:"inventory"`
+		History   []models.InventorySnapshot `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotNil(t, body.Inventory)
+	assert.Equal(t, "6.8.0-47-generic", body.Inventory.Kernel)
+	assert.Equal(t, uint64(34359738368), body.Inventory.TotalRAM)
+	require.Len(t, body.History, 2)
+	assert.Equal(t, "6.8.0-45-generic", body.History[0].Inventory.Kernel)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/inventory/laptop", nil)
+	ts.router.ServeHTTP(w, req)
+	assert.JSONEq(t, `{"inventory":null,"history":[]}`, w.Body.String())
+}
+
+func TestInventoryChangeEvents(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "desktop")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	before := `{"type":"inventory","inventory":{"kernel":"6.8.0-45-generic","microcode":"0xf4","total_ram":17179869184,` +
+		`"disks":[{"name":"sda","model":"WDC WD40EFRX","serial":"WD-1","size":4000787030016},{"name":"sdb","model":"Old SSD","serial":"SSD-1","size":256060514304}],` +
+		`"nics":[{"name":"eth0","mac":"3c:7c:3f:aa:bb:01"},{"name":"eth1","mac":"3c:7c:3f:aa:bb:02"}]}}`
+	// More RAM, a new kernel and microcode, the old SSD replaced and eth1 gone. sda is now called sdb.
+	after := `{"type":"inventory","inventory":{"kernel":"6.8.0-47-generic","microcode":"0xf8","total_ram":34359738368,` +
+		`"disks":[{"name":"sdb","model":"WDC WD40EFRX","serial":"WD-1","size":4000787030016},{"name":"nvme0n1","model":"New SSD","serial":"NV-1","size":1000204886016}],` +
+		`"nics":[{"name":"eth0","mac":"3c:7c:3f:aa:bb:01"}]}}`
+	for _, message := range []string{before, after} {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(message)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	events := ts.wsServer.store.Events("desktop")
+	messages := make(map[string]string)
+	for _, event := range events {
+		messages[event.Type] = event.Message
+	}
+	assert.Len(t, events, 6)
+	assert.Equal(t, map[string]string{
+		"ram_changed":       "Memory changed from 16.0 GiB to 32.0 GiB",
+		"kernel_changed":    "Kernel changed from 6.8.0-45-generic to 6.8.0-47-generic",
+		"microcode_changed": "CPU microcode changed from 0xf4 to 0xf8",
+		"disk_added":        "Disk nvme0n1 added: New SSD (931.5 GiB)",
+		"disk_removed":      "Disk sdb removed: Old SSD (238.5 GiB)",
+		"nic_removed":       "Network interface eth1 (3c:7c:3f:aa:bb:02) disappeared",
+	}, messages)
+}
+
+func TestInventoryRAMTolerance(t *testing.T) {
+	// 16 GiB with a kernel reserving a bit more or less after an upgrade
+	assert.False(t, ramChanged(16<<30, 16<<30))
+	assert.False(t, ramChanged(16663781376, 16647004160))
+	assert.False(t, ramChanged(16647004160, 16663781376))
+	// A module added or removed
+	assert.True(t, ramChanged(16<<30, 32<<30))
+	assert.True(t, ramChanged(32<<30, 24<<30))
+	assert.True(t, ramChanged(0, 8<<30))
+
+	previous := models.Inventory{Kernel: "6.8.0-45-generic", TotalRAM: 16663781376}
+	current := models.Inventory{Kernel: "6.8.0-47-generic", TotalRAM: 16647004160}
+	events := diffInventory(previous, current)
+	require.Len(t, events, 1)
+	assert.Equal(t, "kernel_changed", events[0].Type)
+}
+
+func TestLinkFlappingAlert(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "desktop")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	samples := []string{
+		`{"events":[{"type":"link_down","message":"eth0 went down","details":{"interface":"eth0"}}]}`,
+		`{"events":[{"type":"link_up","message":"eth0 came up","details":{"interface":"eth0"}}]}`,
+		`{"events":[{"type":"link_flap","message":"eth0 lost its link 3 times since the last sample","details":{"interface":"eth0","flaps":"3"}}]}`,
+		// wlan0 is counted on its own
+		`{"events":[{"type":"link_down","message":"wlan0 went down","details":{"interface":"wlan0"}}]}`,
+		`{"events":[{"type":"link_down","message":"eth0 went down","details":{"interface":"eth0"}}]}`,
+		// Only one alert until the link calms down
+		`{"events":[{"type":"link_down","message":"eth0 went down","details":{"interface":"eth0"}}]}`,
+		// Removed interfaces don't count
+		`{"events":[{"type":"link_down","message":"wg0 was removed","details":{"interface":"wg0","removed":"true"}}]}`,
+		`{"events":[{"type":"link_down","message":"wg0 was removed","details":{"interface":"wg0","removed":"true"}}]}`,
+		`{"events":[{"type":"link_down","message":"wg0 was removed","details":{"interface":"wg0","removed":"true"}}]}`,
+		`{"events":[{"type":"link_down","message":"wg0 was removed","details":{"interface":"wg0","removed":"true"}}]}`,
+		`{"events":[{"type":"link_down","message":"wg0 was removed","details":{"interface":"wg0","removed":"true"}}]}`,
+	}
+	for _, sample := range samples {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(sample)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	var alerts []models.Event
+	for _, event := range ts.wsServer.store.Events("desktop") {
+		if event.Type == "link_flapping" {
+			alerts = append(alerts, event)
+		}
+	}
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "eth0 flapped 5 times within the last hour", alerts[0].Message)
+	assert.Equal(t, "5", alerts[0].Details["threshold"])
+}
+
+func TestAuthBurstAlert(t *testing.T) {
+	ts := setupTest()
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	defer ts.server.Close()
+
+	ws, _, err := setupTestClient(ts, "pi")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	samples := []string{
+		`{"auth_ssh_failed":8,"auth_sudo_failed":0,"auth_failed_sources":{"203.0.113.7":8}}`,
+		`{"auth_ssh_failed":0,"auth_sudo_failed":0,"auth_failed_sources":{"203.0.113.7":8}}`,
+		`{"auth_ssh_failed":11,"auth_sudo_failed":1,"auth_failed_sources":{"203.0.113.7":17,"198.51.100.23":2}}`,
+		// Only one alert while the burst lasts
+		`{"auth_ssh_failed":5,"auth_sudo_failed":0,"auth_failed_sources":{"203.0.113.7":22,"198.51.100.23":2}}`,
+	}
+	for _, sample := range samples {
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(sample)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	var alerts []models.Event
+	for _, event := range ts.wsServer.store.Events("pi") {
+		if event.Type == "auth_burst" {
+			alerts = append(alerts, event)
+		}
+	}
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "20 failed logins within the last 5 minutes, mostly from 203.0.113.7 (17), 198.51.100.23 (2)", alerts[0].Message)
+	assert.Equal(t, "20", alerts[0].Details["failures"])
+	assert.Equal(t, "203.0.113.7 (17),198.51.100.23 (2)", alerts[0].Details["sources"])
+}
+
+func TestServeIndexPageUpdates(t *testing.T) {
+	ts := setupTest()
+	ts.router.LoadHTMLGlob("../templates/*")
+	ts.router.GET("/ws", ts.wsServer.HandleClient)
+	ts.router.GET("/", ts.wsServer.ServeIndexPage)
+	defer ts.server.Close()
+
+	samples := map[string]string{
+		"desktop": `{"updates_pending":12,"updates_security":3,"reboot_required":true}`,
+		"laptop":  `{"updates_pending":0,"updates_security":0,"reboot_required":false}`,
+		"nas":     `{"cpu_usage":"5.00%"}`,
+	}
+	for clientID, sample := range samples {
+		ws, _, err := setupTestClient(ts, clientID)
+		require.NoError(t, err)
+		defer ws.Close()
+		require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(sample)))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	ts.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	for clientID := range samples {
+		assert.Contains(t, body, "/analytics/"+clientID)
+	}
+	// Only the desktop needs patching
+	assert.Equal(t, 1, strings.Count(body, "3 security updates"))
+	assert.Equal(t, 1, strings.Count(body, "12 updates"))
+	assert.Equal(t, 1, strings.Count(body, "Reboot required"))
+}
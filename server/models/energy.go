@@ -0,0 +1,60 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxEnergyDays bounds the daily energy history kept for each client
+const MaxEnergyDays = 366
+
+const joulesPerKWh = 3.6e6
+
+// DailyEnergy is the energy a client used on one day
+type DailyEnergy struct {
+	Date string  `json:"date"` // YYYY-MM-DD in the server's time zone
+	KWh  float64 `json:"kwh"`
+}
+
+// AddEnergy adds energy a client reported using at the given time to that day's total
+func (s *Store) AddEnergy(clientID string, at time.Time, joules float64) error {
+	if joules <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	days, ok := s.data.Energy[clientID]
+	if !ok {
+		days = make(map[string]float64)
+		s.data.Energy[clientID] = days
+	}
+	days[at.Format(time.DateOnly)] += joules / joulesPerKWh
+
+	// Drop the oldest days, dates in this format sort chronologically
+	if len(days) > MaxEnergyDays {
+		dates := make([]string, 0, len(days))
+		for date := range days {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+		for _, date := range dates[:len(dates)-MaxEnergyDays] {
+			delete(days, date)
+		}
+	}
+	return s.saveThrottled()
+}
+
+// Energy returns the daily energy totals of a client, oldest first
+func (s *Store) Energy(clientID string) []DailyEnergy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	energy := make([]DailyEnergy, 0, len(s.data.Energy[clientID]))
+	for date, kwh := range s.data.Energy[clientID] {
+		energy = append(energy, DailyEnergy{Date: date, KWh: kwh})
+	}
+	sort.Slice(energy, func(i, j int) bool { return energy[i].Date < energy[j].Date })
+	return energy
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStoreEnergy(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+
+	day := time.Date(2026, 10, 18, 23, 59, 0, 0, time.Local)
+	require.NoError(t, store.AddEnergy("desktop", day, 1.8e6))
+	require.NoError(t, store.AddEnergy("desktop", day, 1.8e6))
+	require.NoError(t, store.AddEnergy("desktop", day.Add(2*time.Minute), 3.6e6))
+	require.NoError(t, store.AddEnergy("desktop", day, -5))
+
+	energy := store.Energy("desktop")
+	require.Len(t, energy, 2)
+	assert.Equal(t, "2026-10-18", energy[0].Date)
+	assert.InDelta(t, 1.0, energy[0].KWh, 1e-9)
+	assert.Equal(t, "2026-10-19", energy[1].Date)
+	assert.InDelta(t, 1.0, energy[1].KWh, 1e-9)
+	assert.Empty(t, store.Energy("laptop"))
+}
+
+func TestStoreEnergyLimit(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	for i := 0; i < MaxEnergyDays+3; i++ {
+		require.NoError(t, store.AddEnergy("desktop", start.AddDate(0, 0, i), 3.6e6))
+	}
+	energy := store.Energy("desktop")
+	assert.Len(t, energy, MaxEnergyDays)
+	assert.Equal(t, "2025-01-04", energy[0].Date)
+}
@@ -0,0 +1,40 @@
+package models
+
+// MaxEventsPerClient bounds the event history kept for each client
+const MaxEventsPerClient = 1000
+
+// Event is a discrete occurrence on a client, either reported by the client
+// itself (e.g. an OOM kill) or detected by the server from its samples
+type Event struct {
+	Type      string            `json:"type"`
+	Timestamp int64             `json:"timestamp"` // Unix seconds
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// AddEvents appends events to a client's history, dropping the oldest beyond MaxEventsPerClient
+func (s *Store) AddEvents(clientID string, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := append(s.data.Events[clientID], events...)
+	if len(history) > MaxEventsPerClient {
+		history = history[len(history)-MaxEventsPerClient:]
+	}
+	s.data.Events[clientID] = history
+	return s.save()
+}
+
+// Events returns a copy of a client's event history, oldest first
+func (s *Store) Events(clientID string) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]Event, len(s.data.Events[clientID]))
+	copy(events, s.data.Events[clientID])
+	return events
+}
@@ -0,0 +1,80 @@
+package models
+
+import "reflect"
+
+// MaxInventoriesPerClient bounds the inventory history kept for each client
+const MaxInventoriesPerClient = 100
+
+// Inventory is the static description of a client host
+type Inventory struct {
+	Hostname     string          `json:"hostname"`
+	OS           string          `json:"os"`
+	Distro       string          `json:"distro"`
+	Kernel       string          `json:"kernel"`
+	Architecture string          `json:"architecture"`
+	CPUModel     string          `json:"cpu_model"`
+	Microcode    string          `json:"microcode,omitempty"`
+	CPUCores     int             `json:"cpu_cores"`
+	CPUThreads   int             `json:"cpu_threads"`
+	TotalRAM     uint64          `json:"total_ram"` // Bytes
+	Disks        []InventoryDisk `json:"disks"`
+	NICs         []InventoryNIC  `json:"nics"`
+	AgentVersion string          `json:"agent_version"`
+}
+
+// InventoryDisk is a physical block device of a client
+type InventoryDisk struct {
+	Name       string `json:"name"`
+	Model      string `json:"model,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	Size       uint64 `json:"size"` // Bytes
+	Rotational bool   `json:"rotational"`
+}
+
+// InventoryNIC is a physical network interface of a client
+type InventoryNIC struct {
+	Name   string `json:"name"`
+	MAC    string `json:"mac"`
+	Driver string `json:"driver,omitempty"`
+}
+
+// InventorySnapshot is an inventory as first received from a client
+type InventorySnapshot struct {
+	Timestamp int64     `json:"timestamp"` // Unix seconds
+	Inventory Inventory `json:"inventory"`
+}
+
+// AddInventory records an inventory received from a client if it differs from the latest one,
+// dropping the oldest snapshots beyond MaxInventoriesPerClient. Returns whether it was recorded
+// and the inventory it replaced as the latest, nil for the first inventory of a client.
+func (s *Store) AddInventory(clientID string, snapshot InventorySnapshot) (*Inventory, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.data.Inventories[clientID]
+	var previous *Inventory
+	if len(history) > 0 {
+		latest := history[len(history)-1].Inventory
+		if reflect.DeepEqual(latest, snapshot.Inventory) {
+			return nil, false, nil
+		}
+		previous = &latest
+	}
+
+	history = append(history, snapshot)
+	if len(history) > MaxInventoriesPerClient {
+		history = history[len(history)-MaxInventoriesPerClient:]
+	}
+	s.data.Inventories[clientID] = history
+	return previous, true, s.save()
+}
+
+// Inventories returns a copy of a client's inventory history, oldest first
+func (s *Store) Inventories(clientID string) []InventorySnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inventories := make([]InventorySnapshot, len(s.data.Inventories[clientID]))
+	copy(inventories, s.data.Inventories[clientID])
+	return inventories
+}
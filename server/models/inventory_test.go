@@ -0,0 +1,56 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreInventories(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.json")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+
+	inventory := Inventory{
+		Hostname: "desktop",
+		Kernel:   "6.8.0-45-generic",
+		TotalRAM: 32 << 30,
+		Disks:    []InventoryDisk{{Name: "nvme0n1", Size: 1 << 40}},
+		NICs:     []InventoryNIC{{Name: "eth0", MAC: "3c:7c:3f:aa:bb:01"}},
+	}
+	previous, added, err := store.AddInventory("desktop", InventorySnapshot{Timestamp: 1700000000, Inventory: inventory})
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Nil(t, previous)
+
+	// Reconnecting with the same inventory adds nothing
+	previous, added, err = store.AddInventory("desktop", InventorySnapshot{Timestamp: 1700000100, Inventory: inventory})
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Nil(t, previous)
+
+	upgraded := inventory
+	upgraded.Kernel = "6.8.0-47-generic"
+	previous, added, err = store.AddInventory("desktop", InventorySnapshot{Timestamp: 1700000200, Inventory: upgraded})
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, &inventory, previous)
+
+	// Inventories survive a restart
+	store, err = NewStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []InventorySnapshot{
+		{Timestamp: 1700000000, Inventory: inventory},
+		{Timestamp: 1700000200, Inventory: upgraded},
+	}, store.Inventories("desktop"))
+	assert.Empty(t, store.Inventories("laptop"))
+
+	for i := 0; i < MaxInventoriesPerClient; i++ {
+		_, _, err := store.AddInventory("desktop", InventorySnapshot{Timestamp: int64(i), Inventory: Inventory{CPUThreads: i}})
+		require.NoError(t, err)
+	}
+	inventories := store.Inventories("desktop")
+	assert.Len(t, inventories, MaxInventoriesPerClient)
+	assert.Equal(t, int64(0), inventories[0].Timestamp)
+}
@@ -0,0 +1,74 @@
+package models
+
+// MaxRebootsPerClient bounds the reboot history kept for each client
+const MaxRebootsPerClient = 500
+
+// Reboot is a restart of a client detected from its boot time
+type Reboot struct {
+	BootTime         int64 `json:"boot_time"`          // Unix seconds
+	PreviousBootTime int64 `json:"previous_boot_time"` // Unix seconds
+	PreviousUptime   int64 `json:"previous_uptime"`    // Seconds the client was up before the reboot
+	LastSeen         int64 `json:"last_seen"`          // Unix seconds of the last sample before the reboot
+	Clean            bool  `json:"clean"`              // The client said goodbye before going down
+}
+
+// AddReboot appends a reboot to a client's history, dropping the oldest beyond MaxRebootsPerClient
+func (s *Store) AddReboot(clientID string, reboot Reboot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := append(s.data.Reboots[clientID], reboot)
+	if len(history) > MaxRebootsPerClient {
+		history = history[len(history)-MaxRebootsPerClient:]
+	}
+	s.data.Reboots[clientID] = history
+	return s.save()
+}
+
+// Reboots returns a copy of a client's reboot history, oldest first
+func (s *Store) Reboots(clientID string) []Reboot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reboots := make([]Reboot, len(s.data.Reboots[clientID]))
+	copy(reboots, s.data.Reboots[clientID])
+	return reboots
+}
+
+// Boot is the latest boot seen of a client, kept so that reboots while the server was down are detected
+type Boot struct {
+	BootTime int64 `json:"boot_time"` // Unix seconds
+	Uptime   int64 `json:"uptime"`    // Seconds, as of the last sample
+	LastSeen int64 `json:"last_seen"` // Unix seconds of the last sample
+	Goodbye  bool  `json:"goodbye"`   // The client said goodbye after its last sample
+}
+
+// SetBoot records the latest boot seen of a client. A new boot or a goodbye is saved right
+// away, further samples of the same boot only update its uptime and are saved throttled.
+func (s *Store) SetBoot(clientID string, boot Boot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.data.Boots[clientID]
+	s.data.Boots[clientID] = boot
+	if !ok || previous.BootTime != boot.BootTime || previous.Goodbye != boot.Goodbye {
+		return s.save()
+	}
+	return s.saveThrottled()
+}
+
+// Boot returns the latest boot seen of a client, the boot of its latest reboot for
+// stores saved before boots were kept
+func (s *Store) Boot(clientID string) (Boot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if boot, ok := s.data.Boots[clientID]; ok {
+		return boot, true
+	}
+	if reboots := s.data.Reboots[clientID]; len(reboots) > 0 {
+		latest := reboots[len(reboots)-1]
+		return Boot{BootTime: latest.BootTime, LastSeen: latest.BootTime}, true
+	}
+	return Boot{}, false
+}
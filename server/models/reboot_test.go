@@ -0,0 +1,60 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreReboots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.json")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+
+	reboot := Reboot{
+		BootTime:         1700090000,
+		PreviousBootTime: 1700000000,
+		PreviousUptime:   86400,
+		LastSeen:         1700086400,
+		Clean:            true,
+	}
+	require.NoError(t, store.AddReboot("desktop", reboot))
+
+	// Reboots survive a restart
+	store, err = NewStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Reboot{reboot}, store.Reboots("desktop"))
+	assert.Empty(t, store.Reboots("laptop"))
+
+	for i := 0; i < MaxRebootsPerClient; i++ {
+		require.NoError(t, store.AddReboot("desktop", Reboot{BootTime: 1800000000}))
+	}
+	reboots := store.Reboots("desktop")
+	assert.Len(t, reboots, MaxRebootsPerClient)
+	assert.Equal(t, int64(1800000000), reboots[0].BootTime)
+}
+
+func TestStoreBoot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.json")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+
+	_, ok := store.Boot("desktop")
+	assert.False(t, ok)
+
+	// Stores saved before boots were kept fall back to the latest reboot
+	require.NoError(t, store.AddReboot("desktop", Reboot{BootTime: 1700090000, PreviousBootTime: 1700000000}))
+	boot, ok := store.Boot("desktop")
+	require.True(t, ok)
+	assert.Equal(t, Boot{BootTime: 1700090000, LastSeen: 1700090000}, boot)
+
+	// A goodbye is saved right away and survives a restart
+	require.NoError(t, store.SetBoot("desktop", Boot{BootTime: 1700090000, Uptime: 600, LastSeen: 1700090600}))
+	require.NoError(t, store.SetBoot("desktop", Boot{BootTime: 1700090000, Uptime: 600, LastSeen: 1700090600, Goodbye: true}))
+	store, err = NewStore(path)
+	require.NoError(t, err)
+	boot, ok = store.Boot("desktop")
+	require.True(t, ok)
+	assert.Equal(t, Boot{BootTime: 1700090000, Uptime: 600, LastSeen: 1700090600, Goodbye: true}, boot)
+}
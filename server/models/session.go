@@ -0,0 +1,72 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// MaxSessionsPerClient bounds the gaming session history kept for each client
+const MaxSessionsPerClient = 500
+
+// SessionStat summarizes a metric over a session
+type SessionStat struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P95 float64 `json:"p95"`
+}
+
+// Session is a period during which a game ran on a client
+type Session struct {
+	ID      string                 `json:"id"`
+	Game    string                 `json:"game"`
+	Start   int64                  `json:"start"` // Unix seconds
+	End     int64                  `json:"end"`   // Unix seconds
+	Samples int                    `json:"samples"`
+	Stats   map[string]SessionStat `json:"stats"` // e.g. cpu_temp, cpu_usage, ram_usage, fps
+}
+
+// NewSessionStat summarizes values, the 95th percentile uses the nearest-rank method
+func NewSessionStat(values []float64) SessionStat {
+	if len(values) == 0 {
+		return SessionStat{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, value := range sorted {
+		sum += value
+	}
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+
+	return SessionStat{
+		Min: sorted[0],
+		Avg: math.Round(sum/float64(len(sorted))*100) / 100,
+		Max: sorted[len(sorted)-1],
+		P95: sorted[max(rank, 0)],
+	}
+}
+
+// AddSession appends a finished session to a client's history, dropping the oldest beyond MaxSessionsPerClient
+func (s *Store) AddSession(clientID string, session Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := append(s.data.Sessions[clientID], session)
+	if len(history) > MaxSessionsPerClient {
+		history = history[len(history)-MaxSessionsPerClient:]
+	}
+	s.data.Sessions[clientID] = history
+	return s.save()
+}
+
+// Sessions returns a copy of a client's session history, oldest first
+func (s *Store) Sessions(clientID string) []Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]Session, len(s.data.Sessions[clientID]))
+	copy(sessions, s.data.Sessions[clientID])
+	return sessions
+}
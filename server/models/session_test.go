@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSessionStat(t *testing.T) {
+	values := make([]float64, 0, 20)
+	for i := 20; i >= 1; i-- {
+		values = append(values, float64(i))
+	}
+	assert.Equal(t, SessionStat{Min: 1, Avg: 10.5, Max: 20, P95: 19}, NewSessionStat(values))
+	assert.Equal(t, SessionStat{Min: 60, Avg: 60, Max: 60, P95: 60}, NewSessionStat([]float64{60}))
+	assert.Equal(t, SessionStat{}, NewSessionStat(nil))
+}
+
+func TestStoreSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.json")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+
+	session := Session{
+		ID:      "1700000000-cs2",
+		Game:    "cs2",
+		Start:   1700000000,
+		End:     1700003600,
+		Samples: 1800,
+		Stats:   map[string]SessionStat{"fps": {Min: 90, Avg: 143, Max: 200, P95: 180}},
+	}
+	require.NoError(t, store.AddSession("desktop", session))
+
+	// Sessions survive a restart
+	store, err = NewStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Session{session}, store.Sessions("desktop"))
+	assert.Empty(t, store.Sessions("laptop"))
+
+	for i := 0; i < MaxSessionsPerClient; i++ {
+		require.NoError(t, store.AddSession("desktop", Session{ID: "later"}))
+	}
+	sessions := store.Sessions("desktop")
+	assert.Len(t, sessions, MaxSessionsPerClient)
+	assert.Equal(t, "later", sessions[0].ID)
+}
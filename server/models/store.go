@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// saveInterval is how often frequent, low value updates such as energy totals are written to disk
+const saveInterval = 30 * time.Second
+
+// storeData is the persisted part of the Store
+type storeData struct {
+	Events      map[string][]Event             `json:"events"`
+	Energy      map[string]map[string]float64  `json:"energy"` // client -> day -> kWh
+	Sessions    map[string][]Session           `json:"sessions"`
+	Reboots     map[string][]Reboot            `json:"reboots"`
+	Inventories map[string][]InventorySnapshot `json:"inventories"`
+	Boots       map[string]Boot                `json:"boots"`
+}
+
+// Store keeps per-client history that outlives WebSocket connections. It lives
+// in memory and, when created with a path, is saved to a JSON file on every change.
+type Store struct {
+	mu      sync.RWMutex
+	path    string
+	data    storeData
+	savedAt time.Time
+}
+
+// NewStore creates a Store backed by the JSON file at path, loading it if it
+// exists. An empty path keeps everything in memory only.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+		data: storeData{
+			Events:      make(map[string][]Event),
+			Energy:      make(map[string]map[string]float64),
+			Sessions:    make(map[string][]Session),
+			Reboots:     make(map[string][]Reboot),
+			Inventories: make(map[string][]InventorySnapshot),
+			Boots:       make(map[string]Boot),
+		},
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &s.data); err != nil {
+		return nil, err
+	}
+	if s.data.Events == nil {
+		s.data.Events = make(map[string][]Event)
+	}
+	if s.data.Energy == nil {
+		s.data.Energy = make(map[string]map[string]float64)
+	}
+	if s.data.Sessions == nil {
+		s.data.Sessions = make(map[string][]Session)
+	}
+	if s.data.Reboots == nil {
+		s.data.Reboots = make(map[string][]Reboot)
+	}
+	if s.data.Inventories == nil {
+		s.data.Inventories = make(map[string][]InventorySnapshot)
+	}
+	if s.data.Boots == nil {
+		s.data.Boots = make(map[string]Boot)
+	}
+	return s, nil
+}
+
+// save writes the store to disk. Callers must hold the write lock.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	content, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated store behind
+	tmp := s.path + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	s.savedAt = time.Now()
+	return os.Rename(tmp, s.path)
+}
+
+// saveThrottled saves the store unless it was saved within saveInterval, for updates
+// that arrive with every sample. At most saveInterval worth of them is lost on a crash.
+// Callers must hold the write lock.
+func (s *Store) saveThrottled() error {
+	if time.Since(s.savedAt) < saveInterval {
+		return nil
+	}
+	return s.save()
+}
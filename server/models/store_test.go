@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chronicle.json")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+	assert.Empty(t, store.Events("desktop"))
+
+	event := Event{Type: "oom_kill", Timestamp: 1700000000, Message: "OOM killer terminated steam (pid 4321)"}
+	require.NoError(t, store.AddEvents("desktop", event))
+	assert.Equal(t, []Event{event}, store.Events("desktop"))
+	assert.Empty(t, store.Events("laptop"))
+
+	// History survives a restart
+	reloaded, err := NewStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{event}, reloaded.Events("desktop"))
+}
+
+func TestStoreEventsLimit(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+
+	for i := 0; i < MaxEventsPerClient+5; i++ {
+		require.NoError(t, store.AddEvents("desktop", Event{Type: "oom_kill", Timestamp: int64(i)}))
+	}
+	events := store.Events("desktop")
+	assert.Len(t, events, MaxEventsPerClient)
+	assert.Equal(t, int64(5), events[0].Timestamp)
+}
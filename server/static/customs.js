@@ -5,6 +5,25 @@ function extractUnit(value) {
     return match ? match[0] : '';
 }
 
+// Maximum number of points kept per chart series
+const MAX_POINTS = 500;
+
+// Append a value to a chart data array, dropping the oldest point past MAX_POINTS
+function pushPoint(data, value) {
+    data.push(value);
+    if (data.length > MAX_POINTS) {
+        data.shift();
+    }
+}
+
+// Returns the formatted metric or '-' (an ECharts gap) when the client did not report it
+function metricValue(data, key) {
+    if (data[key] === undefined) {
+        return '-';
+    }
+    return formatValue(data[key]);
+}
+
 function formatValue(value) {
     if (value === undefined || value === null) {
         return { value: 0, unit: '' };
@@ -14,6 +33,418 @@ function formatValue(value) {
     return { value: numericValue, unit: unit };
 }
 
+// Labels used for event types on the timeline and in the events list
+const EVENT_LABELS = {
+    oom_kill: 'OOM kill',
+    low_battery: 'Low battery',
+    probe_down: 'Probe down',
+    probe_up: 'Probe up',
+    game_started: 'Game started',
+    game_stopped: 'Game stopped',
+    throttling: 'Throttling',
+    reboot: 'Reboot',
+    ram_changed: 'RAM changed',
+    kernel_changed: 'Kernel changed',
+    microcode_changed: 'Microcode changed',
+    disk_added: 'Disk added',
+    disk_removed: 'Disk removed',
+    nic_removed: 'NIC removed',
+    link_down: 'Link down',
+    link_up: 'Link up',
+    link_flap: 'Link flap',
+    ip_changed: 'IP changed',
+    link_flapping: 'Link flapping',
+    auth_burst: 'Failed logins',
+    raid_degraded: 'RAID degraded',
+    raid_recovered: 'RAID recovered',
+    btrfs_errors: 'Btrfs errors',
+    mount_down: 'Mount down',
+    mount_up: 'Mount up'
+};
+
+function eventLabel(event) {
+    return EVENT_LABELS[event.type] || event.type;
+}
+
+// Add an event to the top of the events list
+function renderEvent(event) {
+    document.getElementById('noEvents').style.display = 'none';
+
+    const item = document.createElement('li');
+    item.className = 'list-group-item d-flex justify-content-between align-items-start';
+
+    const description = document.createElement('div');
+    const badge = document.createElement('span');
+    badge.className = 'badge bg-danger me-2';
+    badge.textContent = eventLabel(event);
+    description.appendChild(badge);
+    description.appendChild(document.createTextNode(event.message));
+
+    const when = document.createElement('small');
+    when.className = 'text-muted';
+    when.textContent = new Date(event.timestamp * 1000).toLocaleString();
+
+    item.appendChild(description);
+    item.appendChild(when);
+    document.getElementById('eventList').prepend(item);
+}
+
+// The event history stored by the server for this client, once loaded
+let eventHistory = Promise.resolve([]);
+
+// Load the event history stored by the server for this client
+function loadEvents() {
+    eventHistory = fetch(`/events/${window.clientID}`)
+        .then(response => response.json())
+        .then(body => {
+            body.events.forEach(renderEvent);
+            return body.events;
+        })
+        .catch(error => {
+            console.log("Failed to load events:", error);
+            return [];
+        });
+}
+
+// Metrics summarized per gaming session, in comparison order
+const SESSION_STATS = [
+    { key: 'fps', label: 'FPS', unit: '' },
+    { key: 'cpu_temp', label: 'CPU Temp', unit: '°C' },
+    { key: 'cpu_usage', label: 'CPU Usage', unit: '%' },
+    { key: 'ram_usage', label: 'RAM Usage', unit: '%' }
+];
+
+// Gaming sessions of this client, newest first, and the ones picked for comparison
+let sessions = [];
+let comparedSessions = [];
+
+function formatDuration(seconds) {
+    const minutes = Math.round(seconds / 60);
+    return `${Math.floor(minutes / 60)}h ${minutes % 60}m`;
+}
+
+function sessionStat(session, key, field, unit) {
+    const stat = session.stats[key];
+    return stat ? `${stat[field].toFixed(1)}${unit}` : '--';
+}
+
+// Show the sessions picked for comparison side by side
+function renderSessionComparison() {
+    const picked = sessions.filter(session => comparedSessions.includes(session.id));
+    document.getElementById('sessionComparison').style.display = picked.length === 2 ? '' : 'none';
+    if (picked.length !== 2) {
+        return;
+    }
+
+    const head = document.getElementById('sessionComparisonHead');
+    head.replaceChildren();
+    const headRow = document.createElement('tr');
+    ['', ...picked.map(session => `${session.game} (${new Date(session.start * 1000).toLocaleString()})`)].forEach(text => {
+        const cell = document.createElement('th');
+        cell.textContent = text;
+        headRow.appendChild(cell);
+    });
+    head.appendChild(headRow);
+
+    const body = document.getElementById('sessionComparisonBody');
+    body.replaceChildren();
+    const rows = [['Duration', ...picked.map(session => formatDuration(session.end - session.start))]];
+    SESSION_STATS.forEach(stat => {
+        rows.push([`${stat.label} min / avg / max / p95`, ...picked.map(session =>
+            ['min', 'avg', 'max', 'p95'].map(field => sessionStat(session, stat.key, field, stat.unit)).join(' / '))]);
+    });
+    rows.forEach(values => {
+        const row = document.createElement('tr');
+        values.forEach(text => {
+            const cell = document.createElement('td');
+            cell.textContent = text;
+            row.appendChild(cell);
+        });
+        body.appendChild(row);
+    });
+}
+
+// Load the gaming sessions stored by the server for this client
+function loadSessions() {
+    fetch(`/sessions/${window.clientID}`)
+        .then(response => response.json())
+        .then(body => {
+            sessions = body.sessions.reverse();
+            document.getElementById('noSessions').style.display = sessions.length ? 'none' : '';
+            document.getElementById('sessions').style.display = sessions.length ? '' : 'none';
+
+            const table = document.getElementById('sessionTable');
+            table.replaceChildren();
+            sessions.forEach(session => {
+                const row = document.createElement('tr');
+
+                // Picking a third session replaces the oldest pick
+                const pick = document.createElement('input');
+                pick.type = 'checkbox';
+                pick.className = 'form-check-input';
+                pick.checked = comparedSessions.includes(session.id);
+                pick.addEventListener('change', () => {
+                    comparedSessions = comparedSessions.filter(id => id !== session.id);
+                    if (pick.checked) {
+                        comparedSessions.push(session.id);
+                    }
+                    comparedSessions = comparedSessions.slice(-2);
+                    table.querySelectorAll('input').forEach((input, index) => {
+                        input.checked = comparedSessions.includes(sessions[index].id);
+                    });
+                    renderSessionComparison();
+                });
+                const pickCell = document.createElement('td');
+                pickCell.appendChild(pick);
+                row.appendChild(pickCell);
+
+                [session.game,
+                    new Date(session.start * 1000).toLocaleString(),
+                    formatDuration(session.end - session.start),
+                    sessionStat(session, 'fps', 'avg', ''),
+                    sessionStat(session, 'cpu_temp', 'max', '°C'),
+                    sessionStat(session, 'cpu_usage', 'avg', '%')].forEach(text => {
+                    const cell = document.createElement('td');
+                    cell.textContent = text;
+                    row.appendChild(cell);
+                });
+                table.appendChild(row);
+            });
+            renderSessionComparison();
+        })
+        .catch(error => console.log("Failed to load sessions:", error));
+}
+
+// Format seconds of uptime the way clients report it, e.g. "1d 2h 3m"
+function formatUptime(seconds) {
+    const days = Math.floor(seconds / 86400);
+    const hours = Math.floor(seconds % 86400 / 3600);
+    const minutes = Math.floor(seconds % 3600 / 60);
+    return `${days}d ${hours}h ${minutes}m`;
+}
+
+// Load the reboots the server detected for this client, newest first
+function loadReboots() {
+    fetch(`/reboots/${window.clientID}`)
+        .then(response => response.json())
+        .then(body => {
+            const reboots = body.reboots.reverse();
+            document.getElementById('noReboots').style.display = reboots.length ? 'none' : '';
+            document.getElementById('reboots').style.display = reboots.length ? '' : 'none';
+
+            const table = document.getElementById('rebootTable');
+            table.replaceChildren();
+            reboots.forEach(reboot => {
+                const row = document.createElement('tr');
+                [new Date(reboot.boot_time * 1000).toLocaleString(),
+                    formatUptime(reboot.previous_uptime),
+                    new Date(reboot.last_seen * 1000).toLocaleString()].forEach(text => {
+                    const cell = document.createElement('td');
+                    cell.textContent = text;
+                    row.appendChild(cell);
+                });
+
+                const shutdown = document.createElement('span');
+                shutdown.className = reboot.clean ? 'badge bg-success' : 'badge bg-danger';
+                shutdown.textContent = reboot.clean ? 'Clean' : 'Crash or power loss';
+                const shutdownCell = document.createElement('td');
+                shutdownCell.appendChild(shutdown);
+                row.appendChild(shutdownCell);
+
+                table.appendChild(row);
+            });
+        })
+        .catch(error => console.log("Failed to load reboots:", error));
+}
+
+// Format a size in bytes with binary units, e.g. "931.5 GiB"
+function formatBytes(bytes) {
+    const units = ['B', 'KiB', 'MiB', 'GiB', 'TiB'];
+    let index = 0;
+    while (bytes >= 1024 && index < units.length - 1) {
+        bytes /= 1024;
+        index++;
+    }
+    return `${bytes.toFixed(index ? 1 : 0)} ${units[index]}`;
+}
+
+// Fill a table body with rows of cells
+function fillTable(tableID, rows) {
+    const table = document.getElementById(tableID);
+    table.replaceChildren();
+    rows.forEach(cells => {
+        const row = document.createElement('tr');
+        cells.forEach(text => {
+            const cell = document.createElement('td');
+            cell.textContent = text;
+            row.appendChild(cell);
+        });
+        table.appendChild(row);
+    });
+}
+
+// Load the host inventory of this client and when it changed
+function loadInventory() {
+    fetch(`/inventory/${window.clientID}`)
+        .then(response => response.json())
+        .then(body => {
+            const inventory = body.inventory;
+            document.getElementById('noInventory').style.display = inventory ? 'none' : '';
+            document.getElementById('inventory').style.display = inventory ? '' : 'none';
+            if (!inventory) {
+                return;
+            }
+
+            fillTable('inventoryTable', [
+                ['Hostname', inventory.hostname],
+                ['Distribution', inventory.distro],
+                ['Kernel', inventory.kernel],
+                ['Architecture', inventory.architecture],
+                ['CPU', `${inventory.cpu_model} (${inventory.cpu_cores} cores, ${inventory.cpu_threads} threads)`],
+                ['Microcode', inventory.microcode || '--'],
+                ['Memory', formatBytes(inventory.total_ram)],
+                ['Agent Version', inventory.agent_version]
+            ]);
+            fillTable('inventoryDisks', (inventory.disks || []).map(disk =>
+                [disk.name, disk.model || '--', formatBytes(disk.size), disk.rotational ? 'HDD' : 'SSD', disk.serial || '--']));
+            fillTable('inventoryNICs', (inventory.nics || []).map(nic =>
+                [nic.name, nic.mac, nic.driver || '--']));
+
+            document.getElementById('inventoryUpdated').textContent = body.history.length > 1
+                ? `Changed ${body.history.length - 1} times, last on ${new Date(body.history[body.history.length - 1].timestamp * 1000).toLocaleString()}`
+                : `First seen ${new Date(body.history[0].timestamp * 1000).toLocaleString()}`;
+        })
+        .catch(error => console.log("Failed to load inventory:", error));
+}
+
+// Returns the series called name in a chart whose series are discovered from the data,
+// creating it padded with gaps so it lines up with the current x axis
+function dynamicSeries(chartOption, name, yAxisIndex) {
+    let series = chartOption.series.find(item => item.name === name);
+    if (!series) {
+        series = {
+            name: name,
+            type: 'line',
+            yAxisIndex: yAxisIndex,
+            showSymbol: false,
+            data: new Array(Math.max(chartOption.xAxis.data.length - 1, 0)).fill('-')
+        };
+        chartOption.series.push(series);
+        chartOption.legend.data.push(name);
+    }
+    return series;
+}
+
+// Pads every dynamic series that got no value this sample so all series stay aligned
+function padDynamicSeries(chartOption, updated) {
+    chartOption.series
+        .filter(series => !updated.has(series))
+        .forEach(series => pushPoint(series.data, '-'));
+}
+
+// Show the latest reading of every hwmon sensor in the sensors table
+function updateSensorTable(sensors) {
+    const body = document.getElementById('sensorTable');
+    body.replaceChildren();
+    sensors.forEach(sensor => {
+        const row = document.createElement('tr');
+        const limit = value => value ? `${value} ${sensor.unit}` : '--';
+        [sensor.id, sensor.name || sensor.label, sensor.role || '--', `${sensor.value} ${sensor.unit}`, limit(sensor.max), limit(sensor.crit)].forEach(text => {
+            const cell = document.createElement('td');
+            cell.textContent = text;
+            row.appendChild(cell);
+        });
+        body.appendChild(row);
+    });
+}
+
+// Show the state of every physical network interface in the interfaces table
+function updateInterfaceTable(data) {
+    const body = document.getElementById('interfaceTable');
+    body.replaceChildren();
+    Object.keys(data)
+        .map(key => key.match(/^net_(.+)_operstate$/))
+        .filter(match => match)
+        .sort((a, b) => a[1].localeCompare(b[1]))
+        .forEach(match => {
+            const iface = match[1];
+            const link = data[`wifi_${iface}_signal`]
+                ? `${data[`wifi_${iface}_signal`]}, ${data[`wifi_${iface}_link_quality`]} quality`
+                : data[`net_${iface}_speed`] || '--';
+            const row = document.createElement('tr');
+            [iface, data[match[0]], link].forEach(text => {
+                const cell = document.createElement('td');
+                cell.textContent = text;
+                row.appendChild(cell);
+            });
+            body.appendChild(row);
+        });
+}
+
+// Show the latest result of every probe the client runs in the probes table
+function updateProbeTable(data) {
+    const body = document.getElementById('probeTable');
+    body.replaceChildren();
+    Object.keys(data)
+        .map(key => key.match(/^probe_(.+)_up$/))
+        .filter(match => match)
+        .sort((a, b) => a[1].localeCompare(b[1]))
+        .forEach(match => {
+            const probe = match[1];
+            const up = data[match[0]] === 1;
+            const detail = up ? (data[`probe_${probe}_status`] || '--') : data[`probe_${probe}_error`];
+            const row = document.createElement('tr');
+            [probe, up ? 'Up' : 'Down', data[`probe_${probe}_latency`] || '--', detail].forEach(text => {
+                const cell = document.createElement('td');
+                cell.textContent = text;
+                row.appendChild(cell);
+            });
+            row.className = up ? '' : 'table-danger';
+            body.appendChild(row);
+        });
+    document.getElementById('probes').style.display = body.children.length ? '' : 'none';
+}
+
+// Show the health of every network mount of the client in the network mounts table
+function updateMountTable(data) {
+    const body = document.getElementById('mountTable');
+    body.replaceChildren();
+    Object.keys(data)
+        .map(key => key.match(/^netmount_(.+)_status$/))
+        .filter(match => match)
+        .sort((a, b) => a[1].localeCompare(b[1]))
+        .forEach(match => {
+            const mount = match[1];
+            const healthy = data[match[0]] === 'healthy';
+            const detail = healthy ? `${data[`netmount_${mount}_used_percent`]} used` : data[`netmount_${mount}_error`];
+            const row = document.createElement('tr');
+            [data[`netmount_${mount}_mountpoint`] || mount, data[match[0]], data[`netmount_${mount}_response_time`] || '--', detail].forEach(text => {
+                const cell = document.createElement('td');
+                cell.textContent = text;
+                row.appendChild(cell);
+            });
+            row.className = healthy ? '' : 'table-danger';
+            body.appendChild(row);
+        });
+    document.getElementById('mounts').style.display = body.children.length ? '' : 'none';
+}
+
+// Load the daily energy totals of this client into the energy chart
+function loadEnergy(chart) {
+    fetch(`/energy/${window.clientID}`)
+        .then(response => response.json())
+        .then(body => {
+            chart.setOption({
+                xAxis: { data: body.energy.map(day => day.date) },
+                series: [{ data: body.energy.map(day => day.kwh.toFixed(3)) }]
+            });
+
+            const today = body.energy.find(day => day.date === new Date().toLocaleDateString('en-CA'));
+            document.getElementById('energyToday').textContent = today ? `${today.kwh.toFixed(3)} kWh` : '--';
+        })
+        .catch(error => console.log("Failed to load energy:", error));
+}
+
 // Format and display values in stat cards
 function updateStatCards(data) {
     // CPU usage
@@ -52,9 +483,29 @@ function updateStatCards(data) {
         document.getElementById('swapUsage').textContent = data.swap_percent;
     }
 
-    // CPU frequency
+    // Power draw of the whole CPU package(s)
+    if (data.power_total) {
+        document.getElementById('powerDraw').textContent = data.power_total;
+    }
+
+    // Battery charge and, while discharging, the estimated time left
+    if (data.batteries) {
+        const battery = data.batteries[0];
+        let text = `${battery.capacity}%`;
+        if (battery.time_to_empty) {
+            const minutes = Math.round(battery.time_to_empty / 60);
+            text += ` (${Math.floor(minutes / 60)}h ${minutes % 60}m)`;
+        } else if (data.ac_online) {
+            text += ' (AC)';
+        }
+        document.getElementById('battery').textContent = text;
+        document.getElementById('batteryCard').style.display = '';
+    }
+
+    // CPU frequency, with the governor of the first core when cpufreq reports one
     if (data.cpu_mhz) {
-        document.getElementById('cpuFreq').textContent = data.cpu_mhz;
+        document.getElementById('cpuFreq').textContent =
+            data.cpu_governor_0 ? `${data.cpu_mhz} (${data.cpu_governor_0})` : data.cpu_mhz;
     }
 }
 
@@ -63,6 +514,28 @@ function initializeCharts() {
     // Performance chart
     const performanceChart = echarts.init(document.getElementById('chart'));
 
+    // Per-core chart
+    const coresChartDom = document.getElementById('coresChart');
+    const coresChart = echarts.init(coresChartDom);
+
+    // Sensors chart
+    const sensorsChartDom = document.getElementById('sensorsChart');
+    const sensorsChart = echarts.init(sensorsChartDom);
+
+    // Power and energy charts
+    const powerChartDom = document.getElementById('powerChart');
+    const powerChart = echarts.init(powerChartDom);
+    const energyChartDom = document.getElementById('energyChart');
+    const energyChart = echarts.init(energyChartDom);
+
+    // Gaming chart
+    const gamingChartDom = document.getElementById('gamingChart');
+    const gamingChart = echarts.init(gamingChartDom);
+
+    // Load and pressure chart
+    const loadChartDom = document.getElementById('loadChart');
+    const loadChart = echarts.init(loadChartDom);
+
     // Storage chart
     const storageChartDom = document.getElementById('storageChart');
     const storageChart = echarts.init(storageChartDom);
@@ -71,14 +544,30 @@ function initializeCharts() {
     const networkChartDom = document.getElementById('networkChart');
     const networkChart = echarts.init(networkChartDom);
 
+    // Wi-Fi link chart
+    const linkChartDom = document.getElementById('linkChart');
+    const linkChart = echarts.init(linkChartDom);
+
+    // Socket chart
+    const socketChartDom = document.getElementById('socketChart');
+    const socketChart = echarts.init(socketChartDom);
+
     // Disk chart
     const diskChartDom = document.getElementById('diskChart');
     const diskChart = echarts.init(diskChartDom);
 
     return {
         performance: performanceChart,
+        cores: coresChart,
+        sensors: sensorsChart,
+        power: powerChart,
+        energy: energyChart,
+        gaming: gamingChart,
+        load: loadChart,
         storage: storageChart,
         network: networkChart,
+        link: linkChart,
+        socket: socketChart,
         disk: diskChart
     };
 }
@@ -106,7 +595,7 @@ function webSocket() {
         },
         legend: {
             type: 'scroll',
-            data: ['Average Chipset Temp', 'CPU Temp', 'CPU Usage', 'Free RAM', 'Used RAM', 'Used RAM Percentage', 'Packets Received', 'Packets Sent'],
+            data: ['Average Chipset Temp', 'CPU Temp', 'CPU Usage', 'Free RAM', 'Used RAM', 'Used RAM Percentage', 'Packets Received', 'Packets Sent', 'Events'],
             selected: {
                 'Packets Received': false,
                 'Packets Sent': false,
@@ -129,10 +618,68 @@ function webSocket() {
             { name: 'Used RAM', type: 'line', data: [], markPoint: { data: [] } },
             { name: 'Used RAM Percentage', type: 'line', data: [], markPoint: { data: [] } },
             { name: 'Packets Received', type: 'line', data: [], markPoint: { data: [] } },
-            { name: 'Packets Sent', type: 'line', data: [], markPoint: { data: [] } }
+            { name: 'Packets Sent', type: 'line', data: [], markPoint: { data: [] } },
+            // Carries no data, only the event markers so they can be toggled from the legend
+            {
+                name: 'Events', type: 'line', data: [],
+                markLine: { symbol: 'none', data: [] },
+                markArea: { itemStyle: { color: 'rgba(231, 76, 60, 0.15)' }, data: [] }
+            }
         ]
     };
 
+    // Event markers currently shown on the performance timeline
+    let timelineMarkers = [];
+
+    // Throttling episodes shaded on the performance timeline, and the Unix time of every
+    // sample so an episode's start can be matched to the sample it falls on
+    let throttleAreas = [];
+    const sampleTimes = [];
+
+    // Shade a throttling episode from its first affected sample to the latest one
+    function addThrottleArea(event, end) {
+        const start = parseInt((event.details || {}).start || event.timestamp, 10);
+        let index = sampleTimes.findIndex(time => time >= start);
+        if (index === -1) {
+            index = sampleTimes.length - 1;
+        }
+        throttleAreas.push([
+            { xAxis: option.xAxis.data[Math.max(index, 0)], name: eventLabel(event) },
+            { xAxis: end }
+        ]);
+    }
+
+    // Shade the throttling episodes of the event history on their own stretch of the
+    // timeline, ahead of the live samples
+    function addHistoricalThrottleAreas(events) {
+        const labels = [];
+        const times = [];
+        events.filter(event => event.type === 'throttling').forEach(event => {
+            const details = event.details || {};
+            const start = parseInt(details.start || event.timestamp, 10);
+            const end = Math.max(parseInt(details.end || start, 10), start + 1);
+            const startLabel = new Date(start * 1000).toLocaleString();
+            const endLabel = new Date(end * 1000).toLocaleString();
+            labels.push(startLabel, endLabel);
+            times.push(start, end);
+            throttleAreas.push([
+                { xAxis: startLabel, name: eventLabel(event) },
+                { xAxis: endLabel }
+            ]);
+        });
+        if (labels.length === 0) {
+            return;
+        }
+
+        option.xAxis.data.unshift(...labels);
+        sampleTimes.unshift(...times);
+        option.series.slice(0, 8).forEach(series => {
+            series.data.unshift(...labels.map(() => ({ value: null, unit: '' })));
+        });
+        option.series[8].markArea.data = throttleAreas;
+        chartDevice.setOption(option);
+    }
+
     // Initialize network chart
     const networkOption = {
         tooltip: { trigger: 'axis' },
@@ -145,17 +692,180 @@ function webSocket() {
         ]
     };
 
-    // Initialize storage chart
+    // Initialize Wi-Fi link chart, signal and noise in dBm on the left axis and link quality on the right
+    const linkOption = {
+        tooltip: { trigger: 'axis' },
+        legend: { type: 'scroll', data: [] },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: [
+            { type: 'value', name: 'dBm', max: 0 },
+            { type: 'value', name: 'Quality', max: 100, axisLabel: { formatter: '{value}%' } }
+        ],
+        series: []
+    };
+
+    // Initialize socket chart, TCP connections by state on the left axis and error rates on the right
+    const socketOption = {
+        tooltip: { trigger: 'axis' },
+        legend: {
+            type: 'scroll',
+            data: ['Established', 'Time Wait', 'Close Wait', 'TCP Listening', 'UDP Listening',
+                'Retransmits', 'TCP Timeouts', 'UDP Receive Errors', 'UDP Buffer Errors'],
+            selected: {
+                'TCP Listening': false,
+                'UDP Listening': false
+            }
+        },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: [
+            { type: 'value', name: 'Sockets' },
+            { type: 'value', name: 'Per second', position: 'right' }
+        ],
+        series: [
+            { name: 'Established', type: 'line', data: [], smooth: true },
+            { name: 'Time Wait', type: 'line', data: [], smooth: true },
+            { name: 'Close Wait', type: 'line', data: [], smooth: true },
+            { name: 'TCP Listening', type: 'line', data: [], smooth: true },
+            { name: 'UDP Listening', type: 'line', data: [], smooth: true },
+            { name: 'Retransmits', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'TCP Timeouts', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'UDP Receive Errors', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'UDP Buffer Errors', type: 'line', yAxisIndex: 1, data: [], smooth: true }
+        ]
+    };
+
+    // Initialize per-core chart, series are added as cores show up in the data
+    const coresOption = {
+        tooltip: { trigger: 'axis' },
+        legend: { type: 'scroll', data: [] },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: [
+            { type: 'value', name: 'Usage', max: 100, axisLabel: { formatter: '{value}%' } },
+            { type: 'value', name: 'MHz' }
+        ],
+        series: []
+    };
+
+    // Initialize sensors chart, temperatures on the left axis and fan speeds on the right
+    const sensorsOption = {
+        tooltip: { trigger: 'axis' },
+        legend: { type: 'scroll', data: [] },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: [
+            { type: 'value', name: '°C' },
+            { type: 'value', name: 'RPM' }
+        ],
+        series: []
+    };
+
+    // Initialize power chart, one series per RAPL domain
+    const powerOption = {
+        tooltip: { trigger: 'axis' },
+        legend: { type: 'scroll', data: [] },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: { type: 'value', name: 'W' },
+        series: []
+    };
+
+    // Initialize daily energy chart, filled from the server's history
+    const energyOption = {
+        title: { text: 'Daily Energy', textStyle: { fontSize: 14 } },
+        tooltip: { trigger: 'axis', valueFormatter: value => `${value} kWh` },
+        xAxis: { type: 'category', data: [] },
+        yAxis: { type: 'value', name: 'kWh' },
+        series: [{ name: 'Energy', type: 'bar', data: [] }]
+    };
+
+    // Initialize gaming chart, frame rate from MangoHud overlaid with temperatures and load
+    const gamingOption = {
+        tooltip: { trigger: 'axis' },
+        legend: {
+            data: ['FPS', '1% Low', 'CPU Temp', 'GPU Temp', 'CPU Usage', 'GPU Load'],
+            selected: { 'GPU Load': false }
+        },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: [
+            { type: 'value', name: 'FPS' },
+            { type: 'value', name: '°C / %', position: 'right' }
+        ],
+        series: [
+            { name: 'FPS', type: 'line', data: [], smooth: true, areaStyle: { opacity: 0.2 } },
+            { name: '1% Low', type: 'line', data: [], smooth: true },
+            { name: 'CPU Temp', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'GPU Temp', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'CPU Usage', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'GPU Load', type: 'line', yAxisIndex: 1, data: [], smooth: true }
+        ]
+    };
+
+    // Initialize load chart, PSI stall percentages share the chart on a second axis
+    const loadOption = {
+        tooltip: { trigger: 'axis' },
+        legend: { data: ['Load 1', 'Load 5', 'Load 15', 'CPU Pressure', 'Memory Pressure', 'IO Pressure'] },
+        xAxis: { type: 'category', boundaryGap: false, data: [] },
+        yAxis: [
+            { type: 'value', name: 'Load' },
+            { type: 'value', name: 'Stall', axisLabel: { formatter: '{value}%' } }
+        ],
+        series: [
+            { name: 'Load 1', type: 'line', data: [], smooth: true },
+            { name: 'Load 5', type: 'line', data: [], smooth: true },
+            { name: 'Load 15', type: 'line', data: [], smooth: true },
+            { name: 'CPU Pressure', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'Memory Pressure', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'IO Pressure', type: 'line', yAxisIndex: 1, data: [], smooth: true }
+        ]
+    };
+
+    // Initialize storage chart, the meminfo breakdown is stacked in MB so it adds up to total RAM
     const storageOption = {
         tooltip: { trigger: 'axis' },
-        legend: { data: ['Free RAM', 'Used RAM', 'Used RAM Percentage', 'Swap Used'] },
+        legend: {
+            type: 'scroll',
+            data: ['Free RAM', 'Used RAM', 'Used RAM Percentage', 'Swap Used',
+                'Apps', 'Buffers', 'Cached', 'Slab', 'Free',
+                'Available', 'Shared', 'Dirty', 'Writeback', 'Huge Pages',
+                'Swap In', 'Swap Out', 'Major Faults'],
+            // The breakdown is hidden until picked from the legend
+            selected: {
+                'Apps': false,
+                'Buffers': false,
+                'Cached': false,
+                'Slab': false,
+                'Free': false,
+                'Available': false,
+                'Shared': false,
+                'Dirty': false,
+                'Writeback': false,
+                'Huge Pages': false,
+                'Swap In': false,
+                'Swap Out': false,
+                'Major Faults': false
+            }
+        },
         xAxis: { type: 'category', boundaryGap: false, data: [] },
-        yAxis: { type: 'value' },
+        yAxis: [
+            { type: 'value', name: 'MB' },
+            { type: 'value', name: 'Pages/s', position: 'right' }
+        ],
         series: [
             { name: 'Free RAM', type: 'line', data: [], smooth: true },
             { name: 'Used RAM', type: 'line', data: [], smooth: true },
             { name: 'Used RAM Percentage', type: 'line', data: [], smooth: true },
-            { name: 'Swap Used', type: 'line', data: [], smooth: true }
+            { name: 'Swap Used', type: 'line', data: [], smooth: true },
+            { name: 'Apps', type: 'line', stack: 'memory', areaStyle: {}, showSymbol: false, data: [] },
+            { name: 'Buffers', type: 'line', stack: 'memory', areaStyle: {}, showSymbol: false, data: [] },
+            { name: 'Cached', type: 'line', stack: 'memory', areaStyle: {}, showSymbol: false, data: [] },
+            { name: 'Slab', type: 'line', stack: 'memory', areaStyle: {}, showSymbol: false, data: [] },
+            { name: 'Free', type: 'line', stack: 'memory', areaStyle: {}, showSymbol: false, data: [] },
+            { name: 'Available', type: 'line', data: [], smooth: true },
+            { name: 'Shared', type: 'line', data: [], smooth: true },
+            { name: 'Dirty', type: 'line', data: [], smooth: true },
+            { name: 'Writeback', type: 'line', data: [], smooth: true },
+            { name: 'Huge Pages', type: 'line', data: [], smooth: true },
+            { name: 'Swap In', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'Swap Out', type: 'line', yAxisIndex: 1, data: [], smooth: true },
+            { name: 'Major Faults', type: 'line', yAxisIndex: 1, data: [], smooth: true }
         ]
     };
 
@@ -196,9 +906,23 @@ function webSocket() {
 
     chartDevice.setOption(option);
     charts.network.setOption(networkOption);
+    charts.link.setOption(linkOption);
+    charts.socket.setOption(socketOption);
+    charts.cores.setOption(coresOption);
+    charts.sensors.setOption(sensorsOption);
+    charts.power.setOption(powerOption);
+    charts.energy.setOption(energyOption);
+    charts.gaming.setOption(gamingOption);
+    charts.load.setOption(loadOption);
     charts.storage.setOption(storageOption);
     charts.disk.setOption(diskOption);
 
+    // Daily energy totals only change slowly, refresh them every minute
+    loadEnergy(charts.energy);
+    const energyTimer = setInterval(() => loadEnergy(charts.energy), 60000);
+
+    eventHistory.then(addHistoricalThrottleAreas);
+
     // Keep track of legend selection
     let currentLegend = chartDevice.getOption().legend[0].selected;
     chartDevice.on('legendselectchanged', function(params) {
@@ -210,17 +934,49 @@ function webSocket() {
         document.getElementById('loading').style.display = 'none';
 
         const data = JSON.parse(event.data);
+
+        // The client sends its inventory on connect and when it changes, the server stored it already
+        if (data.type === 'inventory') {
+            loadInventory();
+            return;
+        }
+
+        // Alerts raised by the server are marked at the latest sample
+        if (data.type === 'events') {
+            const time = option.xAxis.data[option.xAxis.data.length - 1];
+            data.events.forEach(event => {
+                if (time) {
+                    timelineMarkers.push({
+                        xAxis: time,
+                        label: { formatter: eventLabel(event) },
+                        lineStyle: { color: '#e74c3c' }
+                    });
+                }
+                renderEvent(event);
+
+                // The server stored the reboot before raising the event
+                if (event.type === 'reboot') {
+                    loadReboots();
+                }
+            });
+            option.series[8].markLine.data = timelineMarkers;
+            chartDevice.setOption(option);
+            return;
+        }
+
         const time = new Date().toLocaleTimeString();
         //console.log(data);
 
         // Update time for all charts
         option.xAxis.data.push(time);
+        sampleTimes.push(Date.now() / 1000);
         networkOption.xAxis.data.push(time);
         storageOption.xAxis.data.push(time);
 
         // Limit data points for better performance
         if (option.xAxis.data.length > 500) {
             option.xAxis.data.shift();
+            sampleTimes.shift();
             networkOption.xAxis.data.shift();
             storageOption.xAxis.data.shift();
         }
@@ -243,7 +999,7 @@ function webSocket() {
                 option.series[index].data.shift();
             }
 
-            const values = option.series[index].data.map(d => d.value);
+            const values = option.series[index].data.map(d => d.value).filter(value => value !== null);
             const maxValue = Math.max(...values);
             const minValue = Math.min(...values);
 
@@ -253,6 +1009,37 @@ function webSocket() {
             ];
         });
 
+        // Mark events reported with this sample on the timeline
+        (data.events || []).forEach(event => {
+            // Throttling is reported once the episode ended, covering the samples since it started
+            if (event.type === 'throttling') {
+                addThrottleArea(event, time);
+            } else {
+                timelineMarkers.push({
+                    xAxis: time,
+                    label: { formatter: eventLabel(event) },
+                    lineStyle: { color: '#e74c3c' }
+                });
+            }
+            renderEvent(event);
+
+            // The server stored the session before forwarding this sample
+            if (event.type === 'game_stopped') {
+                loadSessions();
+            }
+        });
+
+        // Drop markers that scrolled off the chart
+        timelineMarkers = timelineMarkers.filter(marker => option.xAxis.data.includes(marker.xAxis));
+        option.series[8].markLine.data = timelineMarkers;
+        throttleAreas = throttleAreas.filter(area => option.xAxis.data.includes(area[1].xAxis));
+        throttleAreas.forEach(area => {
+            if (!option.xAxis.data.includes(area[0].xAxis)) {
+                area[0].xAxis = option.xAxis.data[0];
+            }
+        });
+        option.series[8].markArea.data = throttleAreas;
+
         // Update network chart data
         networkOption.series[0].data.push(seriesData[6]);
         networkOption.series[1].data.push(seriesData[7]);
@@ -283,6 +1070,96 @@ function webSocket() {
             storageOption.series[2].data.shift();
         }
 
+        // Update memory breakdown, series 4 onwards follow the order of memoryBreakdownKeys
+        const memoryBreakdownKeys = ['mem_apps', 'mem_buffers', 'mem_cached', 'mem_slab', 'mem_free',
+            'mem_available', 'mem_shared', 'mem_dirty', 'mem_writeback', 'mem_hugepages_total',
+            'mem_swap_in_rate', 'mem_swap_out_rate', 'mem_major_fault_rate'];
+        memoryBreakdownKeys.forEach((key, index) => {
+            pushPoint(storageOption.series[index + 4].data, metricValue(data, key));
+        });
+
+        // Update per-core chart, usage (cpu_core_N) next to actual frequency (cpu_freq_N)
+        pushPoint(coresOption.xAxis.data, time);
+        const updatedCoreSeries = new Set();
+        const cores = Object.keys(data)
+            .map(key => key.match(/^cpu_(core|freq)_(\d+)$/))
+            .filter(match => match)
+            .sort((a, b) => a[2] - b[2] || a[1].localeCompare(b[1]));
+        cores.forEach(match => {
+            const name = match[1] === 'core' ? `Core ${match[2]} Usage` : `Core ${match[2]} MHz`;
+            const series = dynamicSeries(coresOption, name, match[1] === 'core' ? 0 : 1);
+            pushPoint(series.data, formatValue(data[match[0]]));
+            updatedCoreSeries.add(series);
+        });
+        padDynamicSeries(coresOption, updatedCoreSeries);
+
+        // Update sensors chart with every hwmon temperature and fan
+        pushPoint(sensorsOption.xAxis.data, time);
+        const updatedSensorSeries = new Set();
+        (data.sensors || [])
+            .filter(sensor => sensor.kind === 'temp' || sensor.kind === 'fan')
+            .forEach(sensor => {
+                const series = dynamicSeries(sensorsOption, `${sensor.name || sensor.label} (${sensor.id})`, sensor.kind === 'temp' ? 0 : 1);
+                pushPoint(series.data, { value: sensor.value, unit: sensor.unit });
+                updatedSensorSeries.add(series);
+            });
+        padDynamicSeries(sensorsOption, updatedSensorSeries);
+        if (data.sensors) {
+            updateSensorTable(data.sensors);
+        }
+
+        // Update power chart with every domain the client reports, e.g. power_package_0
+        pushPoint(powerOption.xAxis.data, time);
+        const updatedPowerSeries = new Set();
+        Object.keys(data)
+            .filter(key => key.startsWith('power_'))
+            .sort()
+            .forEach(key => {
+                const name = key === 'power_total' ? 'Total' : key.substring('power_'.length).replaceAll('_', ' ');
+                const series = dynamicSeries(powerOption, name, 0);
+                pushPoint(series.data, formatValue(data[key]));
+                updatedPowerSeries.add(series);
+            });
+        padDynamicSeries(powerOption, updatedPowerSeries);
+
+        // Update Wi-Fi link chart with every associated wireless interface, e.g. wifi_wlan0_signal
+        pushPoint(linkOption.xAxis.data, time);
+        const updatedLinkSeries = new Set();
+        Object.keys(data)
+            .map(key => key.match(/^wifi_(.+)_(signal|noise|link_quality)$/))
+            .filter(match => match)
+            .sort((a, b) => a[0].localeCompare(b[0]))
+            .forEach(match => {
+                const name = `${match[1]} ${match[2].replace('_', ' ')}`;
+                const series = dynamicSeries(linkOption, name, match[2] === 'link_quality' ? 1 : 0);
+                pushPoint(series.data, formatValue(data[match[0]]));
+                updatedLinkSeries.add(series);
+            });
+        padDynamicSeries(linkOption, updatedLinkSeries);
+        updateInterfaceTable(data);
+        updateProbeTable(data);
+        updateMountTable(data);
+
+        // Update socket chart, a growing Close Wait count points at a connection leak
+        pushPoint(socketOption.xAxis.data, time);
+        ['tcp_established', 'tcp_time_wait', 'tcp_close_wait', 'tcp_listen', 'udp_listen',
+            'tcp_retrans_rate', 'tcp_timeouts_rate', 'udp_in_errors_rate', 'udp_rcvbuf_errors_rate']
+            .forEach((key, index) => pushPoint(socketOption.series[index].data, metricValue(data, key)));
+
+        // Update gaming chart, FPS is only reported while MangoHud logs a running game
+        pushPoint(gamingOption.xAxis.data, time);
+        ['game_fps', 'game_fps_1_low', 'cpu_temp', 'gpu_temp', 'cpu_usage', 'gpu_load']
+            .forEach((key, index) => pushPoint(gamingOption.series[index].data, metricValue(data, key)));
+
+        // Update load chart, PSI "some" avg10 is the share of time at least one task stalled
+        pushPoint(loadOption.xAxis.data, time);
+        pushPoint(loadOption.series[0].data, metricValue(data, 'load_1'));
+        pushPoint(loadOption.series[1].data, metricValue(data, 'load_5'));
+        pushPoint(loadOption.series[2].data, metricValue(data, 'load_15'));
+        pushPoint(loadOption.series[3].data, metricValue(data, 'psi_cpu_some_avg10'));
+        pushPoint(loadOption.series[4].data, metricValue(data, 'psi_memory_some_avg10'));
+        pushPoint(loadOption.series[5].data, metricValue(data, 'psi_io_some_avg10'));
+
         // Update disk chart if disk data is available
         if (data.disk_used && data.disk_free) {
             const usedData = formatValue(data.disk_used);
@@ -319,6 +1196,13 @@ function webSocket() {
 
         option.tooltip.formatter = tooltipFormatter;
         networkOption.tooltip.formatter = tooltipFormatter;
+        linkOption.tooltip.formatter = tooltipFormatter;
+        socketOption.tooltip.formatter = tooltipFormatter;
+        coresOption.tooltip.formatter = tooltipFormatter;
+        sensorsOption.tooltip.formatter = tooltipFormatter;
+        powerOption.tooltip.formatter = tooltipFormatter;
+        gamingOption.tooltip.formatter = tooltipFormatter;
+        loadOption.tooltip.formatter = tooltipFormatter;
         storageOption.tooltip.formatter = tooltipFormatter;
 
         option.legend.selected = currentLegend;
@@ -326,6 +1210,13 @@ function webSocket() {
         // Update all charts with new options
         chartDevice.setOption(option);
         charts.network.setOption(networkOption);
+        charts.link.setOption(linkOption);
+        charts.socket.setOption(socketOption);
+        charts.cores.setOption(coresOption);
+        charts.sensors.setOption(sensorsOption);
+        charts.power.setOption(powerOption);
+        charts.gaming.setOption(gamingOption);
+        charts.load.setOption(loadOption);
         charts.storage.setOption(storageOption);
         charts.disk.setOption(diskOption);
 
@@ -340,6 +1231,7 @@ function webSocket() {
 
     ws.onclose = function() {
         console.log("Connection lost, retrying...");
+        clearInterval(energyTimer);
         document.getElementById('loading').style.display = 'none';
         document.getElementById('error').style.display = 'block';
         setTimeout(webSocket, 3000);
@@ -414,6 +1306,14 @@ function webSocket() {
         tabElement.addEventListener('shown.bs.tab', function() {
             charts.performance.resize();
             charts.network.resize();
+            charts.link.resize();
+            charts.socket.resize();
+            charts.cores.resize();
+            charts.sensors.resize();
+            charts.power.resize();
+            charts.energy.resize();
+            charts.gaming.resize();
+            charts.load.resize();
             charts.storage.resize();
             charts.disk.resize();
         });
@@ -423,6 +1323,14 @@ function webSocket() {
     window.addEventListener('resize', function() {
         charts.performance.resize();
         charts.network.resize();
+        charts.link.resize();
+        charts.socket.resize();
+        charts.cores.resize();
+        charts.sensors.resize();
+        charts.power.resize();
+        charts.energy.resize();
+        charts.gaming.resize();
+        charts.load.resize();
         charts.storage.resize();
         charts.disk.resize();
     });
@@ -430,7 +1338,7 @@ function webSocket() {
 
 // Set initial chart dimensions
 function setChartDimensions() {
-    const chartDivs = ['chart', 'storageChart', 'networkChart', 'diskChart'];
+    const chartDivs = ['chart', 'coresChart', 'sensorsChart', 'powerChart', 'gamingChart', 'loadChart', 'storageChart', 'networkChart', 'diskChart'];
     const screenHeight = window.innerHeight;
 
     chartDivs.forEach(id => {
@@ -444,6 +1352,10 @@ function setChartDimensions() {
 // Initialize everything when the page loads
 document.addEventListener('DOMContentLoaded', function() {
     setChartDimensions();
+    loadEvents();
+    loadSessions();
+    loadReboots();
+    loadInventory();
     webSocket();
 
     // Set current date for date pickers
@@ -1,6 +1,9 @@
 package utils
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // GetEnv get key environment variable if exist otherwise return default Value
 func GetEnv(key, defaultValue string) string {
@@ -10,3 +13,12 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// GetEnvFloat get key environment variable as a number if exist and valid otherwise return default Value
+func GetEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -18,3 +18,15 @@ func TestGetEnv(t *testing.T) {
 	value = GetEnv("MY_ENV", "default_value")
 	assert.Equal(t, "actual_value", value)
 }
+
+func TestGetEnvFloat(t *testing.T) {
+	os.Unsetenv("MY_ENV")
+	assert.Equal(t, 15.0, GetEnvFloat("MY_ENV", 15))
+
+	os.Setenv("MY_ENV", "not a number")
+	defer os.Unsetenv("MY_ENV")
+	assert.Equal(t, 15.0, GetEnvFloat("MY_ENV", 15))
+
+	os.Setenv("MY_ENV", "7.5")
+	assert.Equal(t, 7.5, GetEnvFloat("MY_ENV", 15))
+}